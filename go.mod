@@ -3,8 +3,8 @@ module github.com/mrcrgl/check-graceful-shutdown
 go 1.16
 
 require (
-	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v1.1.3
 	github.com/spf13/pflag v1.0.5
+	google.golang.org/grpc v1.38.0
 )
@@ -1,7 +1,11 @@
 package options
 
 import (
+	"fmt"
+	"net"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,19 +14,42 @@ const ProjectName = "check-graceful-shutdown"
 func NewConfigWithDefaults() *Config {
 	cfg := new(Config)
 
+	cfg.Process.TerminationSequence = TerminationSequence{
+		{Signal: "SIGTERM"},
+	}
+	cfg.Process.GracefulExitCodes = []int{0, 143}
+	cfg.Process.PrefixOutput = true
+	cfg.Process.PreStop.Type = HookTypeNone
+	cfg.Process.TerminationGracePeriod = time.Second * 30
+	cfg.Process.FreePortEnv = "PORT"
+	cfg.Process.ListenTimeout = time.Second * 60
+
 	cfg.Traffic.Target.Val = url.URL{Path: "/", Host: ":8080", Scheme: "http"}
 	cfg.Traffic.RequestConcurrency = 2
 	cfg.Traffic.RequestTimeout = time.Second * 60
 	cfg.Traffic.BodyReadDelay = time.Second * 5
 
+	cfg.LivenessProbe.Type = ProbeTypeHTTP
 	cfg.LivenessProbe.Target.Val = url.URL{Path: "/health", Host: ":8080", Scheme: "http"}
+	cfg.LivenessProbe.Method = "GET"
 	cfg.LivenessProbe.SuccessThreshold = 1
 	cfg.LivenessProbe.FailureThreshold = 3
 	cfg.LivenessProbe.RequestTimeout = time.Second * 1
 	cfg.LivenessProbe.InitialDelay = time.Second * 0
 	cfg.LivenessProbe.Period = time.Second * 10
 
+	cfg.StartupProbe.Type = ProbeTypeNone
+	cfg.StartupProbe.Target.Val = url.URL{Path: "/health", Host: ":8080", Scheme: "http"}
+	cfg.StartupProbe.Method = "GET"
+	cfg.StartupProbe.SuccessThreshold = 1
+	cfg.StartupProbe.FailureThreshold = 30
+	cfg.StartupProbe.RequestTimeout = time.Second * 1
+	cfg.StartupProbe.InitialDelay = time.Second * 0
+	cfg.StartupProbe.Period = time.Second * 2
+
+	cfg.ReadinessProbe.Type = ProbeTypeHTTP
 	cfg.ReadinessProbe.Target.Val = url.URL{Path: "/health/readiness", Host: ":8080", Scheme: "http"}
+	cfg.ReadinessProbe.Method = "GET"
 	cfg.ReadinessProbe.SuccessThreshold = 1
 	cfg.ReadinessProbe.FailureThreshold = 3
 	cfg.ReadinessProbe.RequestTimeout = time.Second * 1
@@ -34,6 +61,7 @@ func NewConfigWithDefaults() *Config {
 
 type Config struct {
 	ProjectName    string
+	StartupProbe   ProbeConfig
 	LivenessProbe  ProbeConfig
 	ReadinessProbe ProbeConfig
 	Traffic        TrafficConfig
@@ -41,9 +69,146 @@ type Config struct {
 }
 
 type ProcessConfig struct {
-	PID       int
-	Command   string
-	Arguments []string
+	PID                 int
+	Command             string
+	Arguments           []string
+	TerminationSequence TerminationSequence
+	SignalProcessGroup  bool
+	GracefulExitCodes   []int
+	// StdoutFile and StderrFile receive a copy of the output of the process, if set.
+	StdoutFile   string
+	StderrFile   string
+	PrefixOutput bool
+	// NotifySocket provides a NOTIFY_SOCKET to the process to report its state via sd_notify.
+	NotifySocket bool
+	PreStop      HookConfig
+	// TerminationGracePeriod starts with the preStop hook, the process is killed once it expired.
+	TerminationGracePeriod time.Duration
+	// Env are additional KEY=VALUE variables of the process, taking precedence over the ones of EnvFile.
+	Env        []string
+	EnvFile    string
+	WorkingDir string
+	// FreePort picks a free tcp port for the process, see Config.WithPort.
+	FreePort    bool
+	FreePortEnv string
+	// ListenTimeout is the time for the process to accept connections on the traffic target.
+	ListenTimeout time.Duration
+}
+
+// WithPort returns a copy of the config for a process listening on port. All targets using the port of the
+// traffic target are rewritten to port, which is passed to the process in the variable FreePortEnv.
+func (c *Config) WithPort(port int) *Config {
+	cp := *c
+
+	servicePort := c.Traffic.Target.Val.Port()
+	for _, u := range []*URI{
+		&cp.Traffic.Target,
+		&cp.StartupProbe.Target,
+		&cp.LivenessProbe.Target,
+		&cp.ReadinessProbe.Target,
+		&cp.Process.PreStop.Target,
+	} {
+		if len(u.Val.Host) != 0 && u.Val.Port() == servicePort {
+			u.Val.Host = net.JoinHostPort(u.Val.Hostname(), strconv.Itoa(port))
+		}
+	}
+
+	cp.Process.Env = append(append([]string{}, c.Process.Env...), fmt.Sprintf("%s=%d", c.Process.FreePortEnv, port))
+
+	return &cp
+}
+
+// HookConfig describes a lifecycle handler like the kubernetes preStop hook.
+type HookConfig struct {
+	Type    HookType
+	Command string
+	Target  URI
+}
+
+const (
+	HookTypeNone HookType = "none"
+	HookTypeExec HookType = "exec"
+	HookTypeHTTP HookType = "http"
+)
+
+type HookType string
+
+func (h *HookType) String() string {
+	return string(*h)
+}
+
+func (h *HookType) Set(value string) error {
+	switch ht := HookType(value); ht {
+	case HookTypeNone, HookTypeExec, HookTypeHTTP:
+		*h = ht
+	default:
+		return fmt.Errorf("unsupported hook type %q", value)
+	}
+
+	return nil
+}
+
+func (h *HookType) Type() string {
+	return "type"
+}
+
+// TerminationStep is a signal sent to the process and the time to wait for it to exit before the next step.
+type TerminationStep struct {
+	Signal string
+	Wait   time.Duration
+}
+
+func (ts TerminationStep) String() string {
+	if ts.Wait == 0 {
+		return ts.Signal
+	}
+
+	return fmt.Sprintf("%s:%s", ts.Signal, ts.Wait)
+}
+
+// TerminationSequence is the escalation ladder to stop the process, e.g. SIGINT:10s,SIGTERM. Without a final SIGKILL,
+// the process is killed once the termination grace period expired.
+type TerminationSequence []TerminationStep
+
+func (t *TerminationSequence) String() string {
+	steps := make([]string, len(*t))
+	for n, step := range *t {
+		steps[n] = step.String()
+	}
+
+	return strings.Join(steps, ",")
+}
+
+func (t *TerminationSequence) Set(value string) error {
+	sequence := make(TerminationSequence, 0)
+
+	for _, part := range strings.Split(value, ",") {
+		var step TerminationStep
+
+		fields := strings.SplitN(strings.TrimSpace(part), ":", 2)
+		step.Signal = strings.ToUpper(fields[0])
+		if len(step.Signal) == 0 {
+			return fmt.Errorf("missing signal in termination step %q", part)
+		}
+
+		if len(fields) == 2 {
+			wait, err := time.ParseDuration(fields[1])
+			if err != nil {
+				return fmt.Errorf("invalid wait in termination step %q: %s", part, err)
+			}
+			step.Wait = wait
+		}
+
+		sequence = append(sequence, step)
+	}
+
+	*t = sequence
+
+	return nil
+}
+
+func (t *TerminationSequence) Type() string {
+	return "sequence"
 }
 
 type TrafficConfig struct {
@@ -54,12 +219,57 @@ type TrafficConfig struct {
 }
 
 type ProbeConfig struct {
-	RequestTimeout   time.Duration
-	Target           URI
-	InitialDelay     time.Duration
-	Period           time.Duration
-	SuccessThreshold int
-	FailureThreshold int
+	Type                ProbeType
+	RequestTimeout      time.Duration
+	Target              URI
+	Method              string
+	Headers             map[string]string
+	Host                string
+	ExpectedStatusCodes []int
+	BodyRegex           string
+	BodyJSONPath        string
+	BodyJSONValue       string
+	KubeletCompatible   bool
+	Command             string
+	Service             string
+	LogReadyRegex       string
+	LogShutdownRegex    string
+	InitialDelay        time.Duration
+	Period              time.Duration
+	Jitter              float64
+	SuccessThreshold    int
+	FailureThreshold    int
+}
+
+const (
+	ProbeTypeNone   ProbeType = "none"
+	ProbeTypeHTTP   ProbeType = "http"
+	ProbeTypeTCP    ProbeType = "tcp"
+	ProbeTypeExec   ProbeType = "exec"
+	ProbeTypeGRPC   ProbeType = "grpc"
+	ProbeTypeLog    ProbeType = "log"
+	ProbeTypeNotify ProbeType = "notify"
+)
+
+type ProbeType string
+
+func (p *ProbeType) String() string {
+	return string(*p)
+}
+
+func (p *ProbeType) Set(value string) error {
+	switch pt := ProbeType(value); pt {
+	case ProbeTypeNone, ProbeTypeHTTP, ProbeTypeTCP, ProbeTypeExec, ProbeTypeGRPC, ProbeTypeLog, ProbeTypeNotify:
+		*p = pt
+	default:
+		return fmt.Errorf("unsupported probe type %q", value)
+	}
+
+	return nil
+}
+
+func (p *ProbeType) Type() string {
+	return "type"
 }
 
 type URI struct {
@@ -87,7 +297,7 @@ func (u *URI) Type() string {
 func CutProcessConfigFromArgs(args ...string) ([]string, ProcessConfig) {
 	pc := ProcessConfig{}
 
-	var retainedArgs []string
+	retainedArgs := args
 	var dividerPos int
 
 loop:
@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewConfigWithDefaults(t *testing.T) {
@@ -95,6 +96,48 @@ func TestURI_Type(t *testing.T) {
 	}
 }
 
+func TestTerminationSequence_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    TerminationSequence
+		wantErr bool
+	}{
+		{
+			name:  "ok_ladder",
+			value: "SIGINT:10s,sigterm:20s,SIGKILL",
+			want: TerminationSequence{
+				{Signal: "SIGINT", Wait: 10 * time.Second},
+				{Signal: "SIGTERM", Wait: 20 * time.Second},
+				{Signal: "SIGKILL"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "err_invalid_wait",
+			value:   "SIGTERM:soon,SIGKILL",
+			wantErr: true,
+		},
+		{
+			name:    "err_missing_signal",
+			value:   "SIGTERM:10s,,SIGKILL",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got TerminationSequence
+			if err := got.Set(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("TerminationSequence.Set() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TerminationSequence.Set() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCutProcessConfigFromArgs(t *testing.T) {
 	type args struct {
 		args []string
@@ -111,6 +154,12 @@ func TestCutProcessConfigFromArgs(t *testing.T) {
 			want:  []string{"foo", "-b", "bar", "-c", "az"},
 			want1: ProcessConfig{Command: "./second", Arguments: []string{"--foobar", "-bar", "-bar", "config.yaml"}},
 		},
+		{
+			name:  "ok_without_command",
+			args:  args{args: []string{"foo", "--pid", "42"}},
+			want:  []string{"foo", "--pid", "42"},
+			want1: ProcessConfig{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -124,3 +173,38 @@ func TestCutProcessConfigFromArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_WithPort(t *testing.T) {
+	cfg := NewConfigWithDefaults()
+	cfg.Process.Env = []string{"APP_ENV=test"}
+	cfg.LivenessProbe.Target.Val = url.URL{Path: "/health", Host: "127.0.0.1:9090", Scheme: "http"}
+
+	got := cfg.WithPort(40123)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "traffic_target", got: got.Traffic.Target.String(), want: "http://:40123/"},
+		{name: "readiness_target", got: got.ReadinessProbe.Target.String(), want: "http://:40123/health/readiness"},
+		{name: "startup_target", got: got.StartupProbe.Target.String(), want: "http://:40123/health"},
+		{name: "other_port_untouched", got: got.LivenessProbe.Target.String(), want: "http://127.0.0.1:9090/health"},
+		{name: "pre_stop_without_target", got: got.Process.PreStop.Target.String(), want: ""},
+		{name: "original_untouched", got: cfg.Traffic.Target.String(), want: "http://:8080/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("WithPort() target = %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+
+	if want := []string{"APP_ENV=test", "PORT=40123"}; !reflect.DeepEqual(got.Process.Env, want) {
+		t.Errorf("WithPort() env = %v, want %v", got.Process.Env, want)
+	}
+	if want := []string{"APP_ENV=test"}; !reflect.DeepEqual(cfg.Process.Env, want) {
+		t.Errorf("WithPort() modified original env to %v", cfg.Process.Env)
+	}
+}
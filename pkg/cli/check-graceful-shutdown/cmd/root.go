@@ -26,19 +26,27 @@ func NewRootCommand() *cobra.Command {
 		Short: "tool to check if a service supports graceful shutdown",
 		Long:  ``,
 		Run: func(cmd *cobra.Command, args []string) {
-			if cfg.Process.Command == "" {
+			if cfg.Process.Command == "" && cfg.Process.PID == 0 {
 				fail(errors.New("invalid command to execute"))
 			}
 
+			if cfg.Process.Command != "" && cfg.Process.PID != 0 {
+				fail(errors.New("either a command to execute or a pid to attach to is allowed"))
+			}
+
 			c, err := grace.NewConductor(cfg)
 			if err != nil {
 				fail(err)
 			}
 
+			if err := c.Preflight(); err != nil {
+				fail(err)
+			}
+
 			ctx, cancel := context.WithCancel(context.Background())
 
 			go func() {
-				sigCh := make(chan os.Signal)
+				sigCh := make(chan os.Signal, 1)
 
 				signal.Notify(sigCh, syscall.SIGTERM, os.Interrupt)
 
@@ -67,17 +75,36 @@ func NewRootCommand() *cobra.Command {
 		},
 	}
 
-	//root.Flags().IntVarP(&cfg.Process.PID, "pid", "p", 0, "pid of the process")
+	root.Flags().IntVarP(&cfg.Process.PID, "pid", "p", cfg.Process.PID, "pid of an already running process to attach to instead of executing a command")
 	//root.Flags().StringVar(&cfg.Process.Command, "exec", cfg.Process.Command, "command to execute")
+	root.Flags().Var(&cfg.Process.TerminationSequence, "termination-sequence", "signals sent to stop the process, each followed by the time to wait for it to exit, e.g. SIGINT:10s,SIGTERM; the process is killed once the termination grace period expired")
+	root.Flags().BoolVar(&cfg.Process.SignalProcessGroup, "signal-process-group", cfg.Process.SignalProcessGroup, "send termination signals to the whole process group instead of the process only, requires the process to be started")
+	root.Flags().IntSliceVar(&cfg.Process.GracefulExitCodes, "graceful-exit-codes", cfg.Process.GracefulExitCodes, "exit codes of the process considered graceful, 128+n for termination by signal n")
+	root.Flags().StringVar(&cfg.Process.StdoutFile, "stdout-file", cfg.Process.StdoutFile, "file to write a copy of the stdout of the process to")
+	root.Flags().StringVar(&cfg.Process.StderrFile, "stderr-file", cfg.Process.StderrFile, "file to write a copy of the stderr of the process to")
+	root.Flags().BoolVar(&cfg.Process.PrefixOutput, "prefix-output", cfg.Process.PrefixOutput, "prefix each line of the process output on the console with the stream it was written to")
+	root.Flags().BoolVar(&cfg.Process.NotifySocket, "notify-socket", cfg.Process.NotifySocket, "provide a NOTIFY_SOCKET to the process to report its state via sd_notify, implied by probes of the notify type")
+	root.Flags().Var(&cfg.Process.PreStop.Type, "pre-stop-type", "type of the hook run before the termination signal, one of none, exec or http")
+	root.Flags().StringVar(&cfg.Process.PreStop.Command, "pre-stop-command", cfg.Process.PreStop.Command, "shell command run as pre stop hook, used by the exec type")
+	root.Flags().Var(&cfg.Process.PreStop.Target, "pre-stop-target", "url requested with GET as pre stop hook, used by the http type")
+	root.Flags().DurationVar(&cfg.Process.TerminationGracePeriod, "termination-grace-period", cfg.Process.TerminationGracePeriod, "time for the pre stop hook and the termination sequence before the process is killed")
+	root.Flags().StringArrayVar(&cfg.Process.Env, "env", cfg.Process.Env, "additional environment variable KEY=VALUE of the process, can be repeated")
+	root.Flags().StringVar(&cfg.Process.EnvFile, "env-file", cfg.Process.EnvFile, "file with KEY=VALUE lines to add to the environment of the process")
+	root.Flags().StringVar(&cfg.Process.WorkingDir, "working-dir", cfg.Process.WorkingDir, "working directory of the process, defaults to the current directory")
+	root.Flags().BoolVar(&cfg.Process.FreePort, "free-port", cfg.Process.FreePort, "pass a free tcp port to the process and use it for all targets on the port of the traffic target")
+	root.Flags().StringVar(&cfg.Process.FreePortEnv, "free-port-env", cfg.Process.FreePortEnv, "environment variable to pass the free port in")
+	root.Flags().DurationVar(&cfg.Process.ListenTimeout, "listen-timeout", cfg.Process.ListenTimeout, "time for the process to accept connections on the traffic target before probes start, the run fails once it expired")
 	root.Flags().Var(&cfg.Traffic.Target, "traffic-target", "http endpoint to simulate traffic to")
 	root.Flags().IntVar(&cfg.Traffic.RequestConcurrency, "traffic-request-concurrency", cfg.Traffic.RequestConcurrency, "number of concurrent requests to perform")
 	root.Flags().DurationVar(&cfg.Traffic.RequestTimeout, "traffic-request-timeout", cfg.Traffic.RequestTimeout, "http request timeout")
 
+	addProbeFlags(root.Flags(), "startup", &cfg.StartupProbe)
 	addProbeFlags(root.Flags(), "liveness", &cfg.LivenessProbe)
 	addProbeFlags(root.Flags(), "readiness", &cfg.ReadinessProbe)
 
-	var args []string
-	args, cfg.Process = options.CutProcessConfigFromArgs(os.Args...)
+	args, pc := options.CutProcessConfigFromArgs(os.Args...)
+	cfg.Process.Command = pc.Command
+	cfg.Process.Arguments = pc.Arguments
 
 	root.ParseFlags(args)
 
@@ -90,6 +117,12 @@ func fail(err error) {
 }
 
 func addProbeFlags(fs *pflag.FlagSet, kind string, pc *options.ProbeConfig) {
+	fs.Var(
+		&pc.Type,
+		fmt.Sprintf("%s-probe-type", kind),
+		fmt.Sprintf("type of %s checks, one of none, http, tcp, exec, grpc, log or notify", kind),
+	)
+
 	fs.DurationVar(
 		&pc.Period,
 		fmt.Sprintf("%s-probe-period", kind),
@@ -97,6 +130,13 @@ func addProbeFlags(fs *pflag.FlagSet, kind string, pc *options.ProbeConfig) {
 		fmt.Sprintf("period of %s checks", kind),
 	)
 
+	fs.Float64Var(
+		&pc.Jitter,
+		fmt.Sprintf("%s-probe-jitter", kind),
+		pc.Jitter,
+		fmt.Sprintf("fraction of the period to randomly delay the first %s check by, within [0, 1)", kind),
+	)
+
 	fs.DurationVar(
 		&pc.InitialDelay,
 		fmt.Sprintf("%s-probe-initial-delay", kind),
@@ -105,10 +145,10 @@ func addProbeFlags(fs *pflag.FlagSet, kind string, pc *options.ProbeConfig) {
 	)
 
 	fs.DurationVar(
-		&pc.InitialDelay,
+		&pc.RequestTimeout,
 		fmt.Sprintf("%s-probe-request-timeout", kind),
-		pc.InitialDelay,
-		fmt.Sprintf("http timeout for %s checks", kind),
+		pc.RequestTimeout,
+		fmt.Sprintf("timeout for %s checks", kind),
 	)
 
 	fs.IntVar(
@@ -128,6 +168,90 @@ func addProbeFlags(fs *pflag.FlagSet, kind string, pc *options.ProbeConfig) {
 	fs.Var(
 		&pc.Target,
 		fmt.Sprintf("%s-probe-target", kind),
-		fmt.Sprintf("http endpoint to perform %s checks", kind),
+		fmt.Sprintf("endpoint to perform %s checks, e.g. http://:8080/health, tcp://:8080 or grpc://:9090", kind),
+	)
+
+	fs.StringVar(
+		&pc.Method,
+		fmt.Sprintf("%s-probe-method", kind),
+		pc.Method,
+		fmt.Sprintf("http method of %s checks", kind),
+	)
+
+	fs.StringToStringVar(
+		&pc.Headers,
+		fmt.Sprintf("%s-probe-header", kind),
+		pc.Headers,
+		fmt.Sprintf("http headers sent with %s checks, e.g. Authorization=\"Bearer token\"", kind),
+	)
+
+	fs.StringVar(
+		&pc.Host,
+		fmt.Sprintf("%s-probe-host", kind),
+		pc.Host,
+		fmt.Sprintf("http host header sent with %s checks", kind),
+	)
+
+	fs.IntSliceVar(
+		&pc.ExpectedStatusCodes,
+		fmt.Sprintf("%s-probe-expected-status", kind),
+		pc.ExpectedStatusCodes,
+		fmt.Sprintf("http status codes treated as successful %s checks, defaults to any 2xx and 3xx", kind),
+	)
+
+	fs.StringVar(
+		&pc.BodyRegex,
+		fmt.Sprintf("%s-probe-body-regex", kind),
+		pc.BodyRegex,
+		fmt.Sprintf("regular expression the http response body of %s checks must match", kind),
+	)
+
+	fs.StringVar(
+		&pc.BodyJSONPath,
+		fmt.Sprintf("%s-probe-body-json-path", kind),
+		pc.BodyJSONPath,
+		fmt.Sprintf("json path in the http response body of %s checks to compare, e.g. $.status", kind),
+	)
+
+	fs.StringVar(
+		&pc.BodyJSONValue,
+		fmt.Sprintf("%s-probe-body-json-value", kind),
+		pc.BodyJSONValue,
+		fmt.Sprintf("expected value at the json path of %s checks, e.g. UP", kind),
+	)
+
+	fs.BoolVar(
+		&pc.KubeletCompatible,
+		fmt.Sprintf("%s-probe-kubelet-compatible", kind),
+		pc.KubeletCompatible,
+		fmt.Sprintf("perform http %s checks like kubelet: kube-probe user agent, same host redirects only, 10KiB body limit, no keep-alive", kind),
+	)
+
+	fs.StringVar(
+		&pc.Command,
+		fmt.Sprintf("%s-probe-command", kind),
+		pc.Command,
+		fmt.Sprintf("shell command to perform %s checks with, used by the exec type", kind),
+	)
+
+	fs.StringVar(
+		&pc.Service,
+		fmt.Sprintf("%s-probe-service", kind),
+		pc.Service,
+		fmt.Sprintf("service name sent with %s checks, used by the grpc type", kind),
+	)
+
+	fs.StringVar(
+		&pc.LogReadyRegex,
+		fmt.Sprintf("%s-probe-log-ready-regex", kind),
+		pc.LogReadyRegex,
+		fmt.Sprintf("regex matching the output line after which %s succeeds, used by the log type", kind),
+	)
+
+	fs.StringVar(
+		&pc.LogShutdownRegex,
+		fmt.Sprintf("%s-probe-log-shutdown-regex", kind),
+		pc.LogShutdownRegex,
+		fmt.Sprintf("regex matching the output line after which %s fails as the shutdown started, used by the log type", kind),
 	)
 }
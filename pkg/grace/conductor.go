@@ -2,12 +2,18 @@ package grace
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"strconv"
 	"sync"
 
 	"time"
 
 	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/hook"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/listener"
 	"github.com/mrcrgl/check-graceful-shutdown/pkg/probe"
 	"github.com/mrcrgl/check-graceful-shutdown/pkg/process"
 	"github.com/mrcrgl/check-graceful-shutdown/pkg/traffic"
@@ -15,12 +21,35 @@ import (
 )
 
 func NewConductor(cfg *options.Config) (*Conductor, error) {
-	liveness, err := probe.NewHTTPForConfig(cfg.LivenessProbe, probe.Success)
+	if cfg.Process.FreePort {
+		if cfg.Process.PID != 0 {
+			return nil, errors.New("a free port can only be passed to a process started by this tool")
+		}
+
+		port, err := freePort()
+		if err != nil {
+			return nil, err
+		}
+
+		log.Printf("passing free port %d to the process as %s", port, cfg.Process.FreePortEnv)
+		cfg = cfg.WithPort(port)
+	}
+
+	var startup probe.Interface
+	if cfg.StartupProbe.Type != options.ProbeTypeNone {
+		var err error
+		startup, err = probe.NewForConfig(cfg.StartupProbe, probe.Unknown)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create startup probe")
+		}
+	}
+
+	liveness, err := probe.NewForConfig(cfg.LivenessProbe, probe.Success)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create liveness probe")
 	}
 
-	readiness, err := probe.NewHTTPForConfig(cfg.ReadinessProbe, probe.Failure)
+	readiness, err := probe.NewForConfig(cfg.ReadinessProbe, probe.Failure)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create readiness probe")
 	}
@@ -30,27 +59,170 @@ func NewConductor(cfg *options.Config) (*Conductor, error) {
 		return nil, errors.Wrap(err, "failed to create traffic simulator")
 	}
 
-	handler := process.NewHandler(cfg.Process.Command, cfg.Process.Arguments...)
+	terminationSequence := make([]terminationStep, 0, len(cfg.Process.TerminationSequence))
+	for _, step := range cfg.Process.TerminationSequence {
+		signal, err := process.ParseSignal(step.Signal)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid termination sequence")
+		}
+		terminationSequence = append(terminationSequence, terminationStep{signal: signal, wait: step.Wait})
+	}
+	if len(terminationSequence) == 0 {
+		return nil, errors.New("empty termination sequence")
+	}
+	if cfg.Process.ListenTimeout <= 0 {
+		return nil, errors.Errorf("listen timeout of %s must be positive", cfg.Process.ListenTimeout)
+	}
+	if cfg.Process.TerminationGracePeriod <= 0 {
+		return nil, errors.Errorf("termination grace period of %s must be positive", cfg.Process.TerminationGracePeriod)
+	}
+	// the process is killed once the grace period expired, longer waits would never be honoured
+	if wait := sequenceWait(terminationSequence); wait > cfg.Process.TerminationGracePeriod {
+		return nil, errors.Errorf(
+			"termination sequence waits %s in total, longer than the termination grace period of %s",
+			wait, cfg.Process.TerminationGracePeriod,
+		)
+	}
+
+	var preStop hook.Interface
+	if cfg.Process.PreStop.Type != options.HookTypeNone {
+		preStop, err = hook.NewForConfig(cfg.Process.PreStop)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create pre stop hook")
+		}
+	}
+
+	var handler process.Handler
+	if cfg.Process.PID != 0 {
+		if usesProbeType(cfg, options.ProbeTypeLog) {
+			return nil, errors.New("log probes require the process to be started, the output of an attached process is not available")
+		}
+		if usesProbeType(cfg, options.ProbeTypeNotify) {
+			return nil, errors.New("notify probes require the process to be started, an attached process has no notify socket")
+		}
+		if cfg.Process.SignalProcessGroup {
+			return nil, errors.New("signalling the process group requires the process to be started, an attached process may not lead its group")
+		}
+		handler = process.NewAttachHandler(cfg.Process.PID)
+	} else {
+		processCfg := cfg.Process
+		if usesProbeType(cfg, options.ProbeTypeNotify) {
+			processCfg.NotifySocket = true
+		}
+		handler = process.NewHandlerForConfig(processCfg)
+	}
+
+	report := NewReport(simulator.Report(), handler.Report())
+	if startup != nil {
+		report.AddProbe("startup", startup)
+	}
+	report.AddProbe("liveness", liveness)
+	report.AddProbe("readiness", readiness)
+
+	listenerAddr := listenerAddress(cfg.Traffic.Target.Val)
 
 	return &Conductor{
-		processHandler: handler,
-		livenessProbe:  liveness,
-		readinessProbe: readiness,
-		traffic:        simulator,
+		config:              cfg,
+		listenerAddr:        listenerAddr,
+		listener:            listener.NewWatcher(listenerAddr, listenerCheckInterval),
+		listenTimeout:       cfg.Process.ListenTimeout,
+		signalGroup:         cfg.Process.SignalProcessGroup,
+		gracefulExitCodes:   cfg.Process.GracefulExitCodes,
+		terminationSequence: terminationSequence,
+		gracePeriod:         cfg.Process.TerminationGracePeriod,
+		preStop:             preStop,
+		processHandler:      handler,
+		startupProbe:        startup,
+		livenessProbe:       liveness,
+		readinessProbe:      readiness,
+		traffic:             simulator,
+		report:              report,
 	}, nil
 }
 
+// freePort returns a tcp port which is currently not in use.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to find a free port")
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// listenerAddress returns the address the service listens on for traffic to the target. Without an explicit port,
+// the default port of the scheme is used.
+func listenerAddress(target url.URL) string {
+	if len(target.Port()) != 0 {
+		return target.Host
+	}
+
+	switch target.Scheme {
+	case "http":
+		return net.JoinHostPort(target.Hostname(), "80")
+	case "https":
+		return net.JoinHostPort(target.Hostname(), "443")
+	}
+
+	return target.Host
+}
+
+// sequenceWait is the total time the termination sequence waits for the process to exit.
+func sequenceWait(sequence []terminationStep) time.Duration {
+	var wait time.Duration
+	for _, step := range sequence {
+		wait += step.wait
+	}
+
+	return wait
+}
+
+// usesProbeType reports whether any probe of the config is of type t.
+func usesProbeType(cfg *options.Config, t options.ProbeType) bool {
+	for _, pc := range []options.ProbeConfig{cfg.StartupProbe, cfg.LivenessProbe, cfg.ReadinessProbe} {
+		if pc.Type == t {
+			return true
+		}
+	}
+
+	return false
+}
+
 type LifecycleStatus int
 
+// listenerCheckInterval is the time between connection attempts to the listener of the service.
+const listenerCheckInterval = 10 * time.Millisecond
+
+// minimumGracePeriod is the time the process is given after the termination signal at least.
+const minimumGracePeriod = 2 * time.Second
+
+// terminationStep is a signal to send and the time to wait for the process to exit before escalating.
+type terminationStep struct {
+	signal process.Signal
+	wait   time.Duration
+}
+
 type Conductor struct {
-	processHandler process.Handler
-	livenessProbe  probe.Interface
-	readinessProbe probe.Interface
-	traffic        traffic.Simulator
+	config              *options.Config
+	listenerAddr        string
+	listener            *listener.Watcher
+	listenTimeout       time.Duration
+	signalGroup         bool
+	gracefulExitCodes   []int
+	terminationSequence []terminationStep
+	gracePeriod         time.Duration
+	preStop             hook.Interface
+	processHandler      process.Handler
+	startupProbe        probe.Interface
+	livenessProbe       probe.Interface
+	readinessProbe      probe.Interface
+	traffic             traffic.Simulator
+	report              *Report
 }
 
 /*
-start both probes
+start the startup probe, if any, and both probes once it succeeded
 
 start to flow traffic when readiness is green
 stop to flow traffic when readiness is red
@@ -59,35 +231,101 @@ capture errors from simulator and prepare report
 
 // Run starts the test. The function blocks until the test is done. To abort, cancel the context.
 func (c *Conductor) Run(ctx context.Context) {
+	startupCh := make(chan probe.Status)
 	livenessCh := make(chan probe.Status)
 	readinessCh := make(chan probe.Status)
 	processCh := make(chan process.Status)
 
+	if c.startupProbe != nil {
+		c.startupProbe.Notify(startupCh)
+	}
 	c.livenessProbe.Notify(livenessCh)
 	c.readinessProbe.Notify(readinessCh)
 	c.processHandler.Notify(processCh)
 
+	listenerCh := make(chan listener.Event)
+	c.listener.Notify(listenerCh)
+
+	ctxListener, cancelListener := context.WithCancel(ctx)
+	defer cancelListener()
+
+	// state notifications are consumed in any case, as they are recorded in the timeline
+	stateCh := make(chan process.Notification)
+	c.processHandler.NotifyState(stateCh)
+
+	go func() {
+		observers := c.notificationObservers()
+		for n := range stateCh {
+			log.Printf("process notified %s", n)
+			for _, o := range observers {
+				o.ObserveNotification(n.Time, n.Key, n.Value)
+			}
+		}
+	}()
+
+	if observers := c.lineObservers(); len(observers) > 0 {
+		outputCh := make(chan process.OutputLine)
+		c.processHandler.NotifyOutput(outputCh)
+
+		go func() {
+			for line := range outputCh {
+				for _, o := range observers {
+					o.ObserveLine(line.Time, line.Text)
+				}
+			}
+		}()
+	}
+
 	trafficCtx, trafficCancel := context.WithCancel(ctx)
 
 	wg := new(sync.WaitGroup)
 
 	go func() {
 		ctxProbes, cancelProbes := context.WithCancel(ctx)
+		ctxStartup, cancelStartup := context.WithCancel(ctxProbes)
+		defer cancelStartup()
 
 	loop:
 		for {
 			select {
 			case procStatus := <-processCh:
 				log.Printf("process status changed to %s\n", procStatus)
+				c.report.RecordEvent(time.Now(), "process", "status changed to %s", procStatus)
 				switch procStatus {
 				case process.Running:
-					go c.livenessProbe.Run(ctxProbes)
-					go c.readinessProbe.Run(ctxProbes)
+					// probes start once the service accepts connections, instead of failing while it boots
+					go c.listener.Run(ctxListener)
+					go c.followListener(listenerCh, func() {
+						if c.startupProbe != nil {
+							go c.startupProbe.Run(ctxStartup)
+						} else {
+							go c.livenessProbe.Run(ctxProbes)
+							go c.readinessProbe.Run(ctxProbes)
+						}
+					})
 				case process.Exited:
+					if exit, ok := c.processHandler.Report().Exit(); ok {
+						c.report.RecordExit(exit.Time)
+						c.report.RecordEvent(exit.Time, "process", "exited with code %d", exit.Code)
+					} else {
+						c.report.RecordExit(time.Now())
+					}
 					trafficCancel()
 					cancelProbes()
+					cancelListener()
 					break loop
 				}
+			case status := <-startupCh:
+				log.Printf("startup status changed to %s\n", status)
+				c.report.RecordEvent(time.Now(), "startup", "status changed to %s", status)
+				cancelStartup()
+				if status == probe.Success {
+					go c.livenessProbe.Run(ctxProbes)
+					go c.readinessProbe.Run(ctxProbes)
+				} else {
+					c.report.Fail("startup probe failed, service did not start")
+					go c.sendSignal(process.SignalKill)
+				}
 			}
 		}
 	}()
@@ -97,7 +335,8 @@ func (c *Conductor) Run(ctx context.Context) {
 		defer wg.Done()
 
 		if err := c.processHandler.Start(ctx); err != nil {
-			log.Panicf("failed to handle process: %s", err)
+			log.Printf("failed to handle process: %s", err)
+			c.report.Fail("process: %s", err)
 		}
 	}()
 
@@ -107,12 +346,18 @@ func (c *Conductor) Run(ctx context.Context) {
 			select {
 			case status := <-livenessCh:
 				log.Printf("liveness status changed to %s\n", status)
+				c.report.RecordEvent(time.Now(), "liveness", "status changed to %s", status)
 				if status == probe.Failure {
 					break loop
 				}
 			case status := <-readinessCh:
 				log.Printf("readiness status changed to %s\n", status)
+				c.report.RecordEvent(time.Now(), "readiness", "status changed to %s", status)
+				if status == probe.Failure {
+					c.report.RecordReadinessFailure(time.Now())
+				}
 				if status == probe.Success {
+					c.report.RecordEvent(time.Now(), "traffic", "simulation started")
 					go c.traffic.Simulate(trafficCtx, wg)
 					<-time.After(time.Second * 10)
 					go c.initiateShutdown()
@@ -124,24 +369,302 @@ func (c *Conductor) Run(ctx context.Context) {
 	}()
 
 	wg.Wait()
+
+	c.evaluateKill()
+	c.evaluateExit()
+	c.evaluateListenerOwnership()
+}
+
+// lineObservers returns the probes which derive their status from the output of the process.
+func (c *Conductor) lineObservers() []probe.LineObserver {
+	observers := make([]probe.LineObserver, 0)
+	for _, p := range []probe.Interface{c.startupProbe, c.livenessProbe, c.readinessProbe} {
+		if o, ok := p.(probe.LineObserver); ok {
+			observers = append(observers, o)
+		}
+	}
+
+	return observers
+}
+
+// notificationObservers returns the probes which derive their status from sd_notify notifications of the process.
+func (c *Conductor) notificationObservers() []probe.NotificationObserver {
+	observers := make([]probe.NotificationObserver, 0)
+	for _, p := range []probe.Interface{c.startupProbe, c.livenessProbe, c.readinessProbe} {
+		if o, ok := p.(probe.NotificationObserver); ok {
+			observers = append(observers, o)
+		}
+	}
+
+	return observers
 }
 
+// initiateShutdown runs the pre stop hook, if any, and walks the termination sequence until the process exited.
+// Like kubelet, the termination grace period starts with the hook. Once it expired, the process is killed.
 func (c *Conductor) initiateShutdown() {
 	processCh := make(chan process.Status)
+	exitedCh := make(chan struct{})
 
 	c.processHandler.Notify(processCh)
-	c.processHandler.Signal(process.SignalTerminate)
+
+	go func() {
+		for s := range processCh {
+			if s == process.Exited {
+				close(exitedCh)
+				return
+			}
+		}
+	}()
+
+	graceDeadline := time.Now().Add(c.gracePeriod)
+
+	// the listener is watched again to measure when it stops accepting connections
+	c.listener.DetectClose()
+
+	if c.preStop != nil {
+		c.runPreStop(graceDeadline)
+	}
+
+	// like kubelet, the process gets a minimum grace period even if the hook used up the termination grace period
+	remaining := time.Until(graceDeadline)
+	if remaining < minimumGracePeriod {
+		remaining = minimumGracePeriod
+	}
+	graceTimer := time.NewTimer(remaining)
+	defer graceTimer.Stop()
+
+	for _, step := range c.terminationSequence {
+		if step.signal == process.SignalKill {
+			c.kill()
+			return
+		}
+
+		c.sendSignal(step.signal)
+
+		if step.wait == 0 {
+			continue
+		}
+
+		select {
+		case <-exitedCh:
+			return
+		case <-graceTimer.C:
+			c.killAfterGracePeriod()
+			return
+		case <-time.After(step.wait):
+			log.Printf("process still running %s after %s, escalating", step.wait, step.signal)
+		}
+	}
 
 	select {
-	case s := <-processCh:
-		if s == process.Exited {
-			break
+	case <-exitedCh:
+	case <-graceTimer.C:
+		c.killAfterGracePeriod()
+	}
+}
+
+// runPreStop runs the pre stop hook. It is aborted once the termination grace period expired.
+func (c *Conductor) runPreStop(graceDeadline time.Time) {
+	ctx, cancel := context.WithDeadline(context.Background(), graceDeadline)
+	defer cancel()
+
+	c.report.RecordPreStopStarted(c.preStop.String(), time.Now())
+	err := c.preStop.Run(ctx)
+	c.report.RecordPreStopFinished(time.Now(), err)
+
+	switch {
+	case err == nil:
+	case ctx.Err() == context.DeadlineExceeded:
+		log.Printf("pre stop hook %s exceeded the termination grace period: %s", c.preStop, err)
+		c.report.Fail("pre stop hook %s did not finish within the termination grace period of %s", c.preStop, c.gracePeriod)
+	default:
+		log.Printf("pre stop hook %s failed: %s", c.preStop, err)
+		c.report.Fail("pre stop hook %s failed: %s", c.preStop, err)
+	}
+}
+
+func (c *Conductor) killAfterGracePeriod() {
+	log.Printf("process still running after the termination grace period of %s, killing", c.gracePeriod)
+
+	c.kill()
+}
+
+// kill sends SIGKILL to the process during the shutdown and records the traffic requests in flight at that time,
+// which evaluateKill fails the run for. Aborting the run, e.g. on a failed startup, uses sendSignal instead.
+func (c *Conductor) kill() {
+	c.report.RecordKill(time.Now(), c.traffic.Report().InFlight())
+	c.sendSignal(process.SignalKill)
+}
+
+// sendSignal sends a signal to the process and records it.
+func (c *Conductor) sendSignal(signal process.Signal) {
+	c.report.RecordSignal(signal, time.Now())
+	c.processHandler.Signal(signal)
+}
+
+// evaluateKill fails the run if the process had to be killed during the shutdown. This applies even if the exit code
+// is considered graceful, as the process did not finish its shutdown on its own.
+func (c *Conductor) evaluateKill() {
+	killedAt, inFlight, ok := c.report.Kill()
+	if !ok {
+		return
+	}
+
+	if exit, ok := c.processHandler.Report().Exit(); ok && exit.Time.Before(killedAt) {
+		return
+	}
+
+	c.report.Fail(
+		"process did not exit on its own and was killed %s after the shutdown started, %d traffic requests were in flight",
+		killedAt.Sub(c.report.ShutdownStarted()).Round(time.Millisecond), inFlight,
+	)
+}
+
+// evaluateExit fails the run if the process exited with a code not considered graceful. The exit code of a killed
+// process is covered by evaluateKill during the shutdown and by the failure which aborted the run otherwise.
+func (c *Conductor) evaluateExit() {
+	exit, ok := c.processHandler.Report().Exit()
+	if !ok || exit.Code < 0 || exit.Killed {
+		return
+	}
+
+	for _, code := range c.gracefulExitCodes {
+		if exit.Code == code {
+			return
+		}
+	}
+
+	if len(exit.Signal) != 0 {
+		c.report.Fail("process terminated by %s with exit code %d, graceful are %v", exit.Signal, exit.Code, c.gracefulExitCodes)
+		return
+	}
+
+	c.report.Fail("process exited with code %d, graceful are %v", exit.Code, c.gracefulExitCodes)
+}
+
+// inspectListener resolves the processes owning the listener of the traffic target.
+func (c *Conductor) inspectListener() {
+	_, portStr, err := net.SplitHostPort(c.listenerAddr)
+	if err != nil {
+		log.Printf("unable to inspect listener %s: %s", c.listenerAddr, err)
+		return
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		log.Printf("unable to inspect listener %s: %s", c.listenerAddr, err)
+		return
+	}
+
+	owners, err := process.FindListenerOwners(port)
+	if err != nil {
+		log.Printf("unable to inspect listener %s: %s", c.listenerAddr, err)
+		return
+	}
+
+	c.report.RecordListenerOwners(port, owners)
+}
+
+// evaluateListenerOwnership fails the run if the listener is not owned by the signalled process and the termination
+// signal did not reach the owner.
+func (c *Conductor) evaluateListenerOwnership() {
+	port, owners := c.report.ListenerOwners()
+	report := c.processHandler.Report()
+
+	problems, notes := assessListenerOwnership(port, report.PID(), owners, report.Descendants(), c.signalGroup)
+	for _, note := range notes {
+		log.Printf("%s", note)
+		c.report.Note("%s", note)
+	}
+	for _, problem := range problems {
+		c.report.Fail("%s", problem)
+	}
+}
+
+// assessListenerOwnership explains for each owner of the listener whether it got the termination signal sent to pid.
+// Descendants only get it if the whole process group is signalled or pid forwards it, other processes never.
+// Problems are owners which evidently did not get it, notes are descendants which exited on their own before
+// SIGKILL, so pid presumably forwarded the signal.
+func assessListenerOwnership(port, pid int, owners []int, descendants []process.Descendant, signalGroup bool) ([]string, []string) {
+	problems := make([]string, 0)
+	notes := make([]string, 0)
+	if pid == 0 {
+		// the process was never started, there is nothing to compare with
+		return problems, notes
+	}
+
+	for _, owner := range owners {
+		if owner == pid {
+			return problems, notes
+		}
+	}
+
+	byPID := make(map[int]process.Descendant, len(descendants))
+	for _, d := range descendants {
+		byPID[d.PID] = d
+	}
+
+	for _, owner := range owners {
+		d, ok := byPID[owner]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf(
+				"listener on port %d is owned by pid=%d, which is not a descendant of the signalled pid=%d and does not get the termination signal",
+				port, owner, pid,
+			))
+		case signalGroup:
+		case d.AliveAtKill:
+			problems = append(problems, fmt.Sprintf(
+				"listener on port %d is owned by pid=%d (%s), but the termination signal was sent to pid=%d which did not forward it",
+				port, d.PID, d.Command, pid,
+			))
+		default:
+			notes = append(notes, fmt.Sprintf(
+				"listener on port %d is owned by pid=%d (%s), which exited on its own after the termination signal was sent to pid=%d",
+				port, d.PID, d.Command, pid,
+			))
+		}
+	}
+
+	return problems, notes
+}
+
+// followListener records the listener events of the service. onListening is called the first time the service
+// accepts connections. If that does not happen within the listen timeout, the run fails and the process is killed.
+func (c *Conductor) followListener(events <-chan listener.Event, onListening func()) {
+	timeout := time.NewTimer(c.listenTimeout)
+	defer timeout.Stop()
+
+	listening := false
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			log.Printf("listener on %s %s\n", c.listenerAddr, e.Status)
+
+			switch e.Status {
+			case listener.Listening:
+				c.report.RecordEvent(e.Time, "listener", "listening on %s", c.listenerAddr)
+				if !listening {
+					listening = true
+					timeout.Stop()
+					go c.inspectListener()
+					onListening()
+				}
+			case listener.Closed:
+				c.report.RecordListenerClosed(e.Time)
+			}
+		case <-timeout.C:
+			c.report.Fail("service did not listen on %s within %s", c.listenerAddr, c.listenTimeout)
+			c.sendSignal(process.SignalKill)
 		}
-	case <-time.After(time.Second * 30):
-		c.processHandler.Signal(process.SignalKill)
 	}
 }
 
-func (c *Conductor) Report() *traffic.SimulationReport {
-	return c.traffic.Report()
+func (c *Conductor) Report() *Report {
+	return c.report
 }
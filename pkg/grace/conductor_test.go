@@ -0,0 +1,768 @@
+package grace
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/listener"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/probe"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/process"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/traffic"
+)
+
+func TestNewConductor_terminationSequence(t *testing.T) {
+	tests := []struct {
+		name        string
+		sequence    string
+		gracePeriod time.Duration
+		wantErr     bool
+	}{
+		{name: "ok_default", gracePeriod: 30 * time.Second},
+		{name: "ok_within_grace_period", sequence: "SIGINT:10s,SIGTERM:20s", gracePeriod: 30 * time.Second},
+		{name: "ok_explicit_kill", sequence: "SIGINT:10s,SIGTERM:10s,SIGKILL", gracePeriod: 30 * time.Second},
+		{name: "err_exceeds_grace_period", sequence: "SIGTERM:30s,SIGKILL", gracePeriod: 20 * time.Second, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := options.NewConfigWithDefaults()
+			cfg.Process.Command = "/bin/true"
+			cfg.Process.TerminationGracePeriod = tt.gracePeriod
+			if len(tt.sequence) != 0 {
+				if err := cfg.Process.TerminationSequence.Set(tt.sequence); err != nil {
+					t.Fatalf("TerminationSequence.Set() error = %v", err)
+				}
+			}
+
+			if _, err := NewConductor(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("NewConductor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewConductor_attach(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *options.Config)
+		wantErr bool
+	}{
+		{name: "ok", modify: func(cfg *options.Config) {}},
+		{name: "err_signal_process_group", modify: func(cfg *options.Config) { cfg.Process.SignalProcessGroup = true }, wantErr: true},
+		{name: "err_free_port", modify: func(cfg *options.Config) { cfg.Process.FreePort = true }, wantErr: true},
+		{name: "err_log_probe", modify: func(cfg *options.Config) { cfg.ReadinessProbe.Type = options.ProbeTypeLog }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := options.NewConfigWithDefaults()
+			cfg.Process.PID = 1
+			tt.modify(cfg)
+
+			if _, err := NewConductor(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("NewConductor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_listenerAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{name: "explicit_port", target: "http://:8080/", want: ":8080"},
+		{name: "http_default_port", target: "http://localhost/", want: "localhost:80"},
+		{name: "https_default_port", target: "https://svc/", want: "svc:443"},
+		{name: "ipv6_default_port", target: "http://[::1]/", want: "[::1]:80"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target options.URI
+			if err := target.Set(tt.target); err != nil {
+				t.Fatalf("URI.Set() error = %v", err)
+			}
+
+			if got := listenerAddress(target.Val); got != tt.want {
+				t.Errorf("listenerAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewConductor_listenerAddress(t *testing.T) {
+	cfg := options.NewConfigWithDefaults()
+	cfg.Process.Command = "/bin/true"
+	if err := cfg.Traffic.Target.Set("http://localhost/"); err != nil {
+		t.Fatalf("URI.Set() error = %v", err)
+	}
+
+	c, err := NewConductor(cfg)
+	if err != nil {
+		t.Fatalf("NewConductor() error = %v", err)
+	}
+
+	if c.listenerAddr != "localhost:80" {
+		t.Errorf("NewConductor() listener address = %q, want %q", c.listenerAddr, "localhost:80")
+	}
+}
+
+func TestConductor_Run_startupProbe(t *testing.T) {
+	tests := []struct {
+		name          string
+		startupStatus probe.Status
+		wantProbes    bool
+		wantFailures  []string
+		wantSignals   []process.Signal
+	}{
+		{
+			name:          "ok_starts_probes",
+			startupStatus: probe.Success,
+			wantProbes:    true,
+		},
+		{
+			name:          "err_kills_process",
+			startupStatus: probe.Failure,
+			wantProbes:    false,
+			wantFailures:  []string{"startup probe failed"},
+			wantSignals:   []process.Signal{process.SignalKill},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newFakeHandler()
+			startup, liveness, readiness := newFakeProbe(), newFakeProbe(), newFakeProbe()
+			c := newTestConductor(t, handler, listen(t))
+			c.startupProbe, c.livenessProbe, c.readinessProbe = startup, liveness, readiness
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				c.Run(ctx)
+			}()
+
+			waitFor(t, "startup probe started", startup.started)
+			if liveness.wasStarted() || readiness.wasStarted() {
+				t.Fatalf("liveness or readiness started before the startup probe succeeded")
+			}
+
+			startup.set(t, tt.startupStatus)
+
+			if tt.wantProbes {
+				waitFor(t, "liveness probe started", liveness.started)
+				waitFor(t, "readiness probe started", readiness.started)
+				cancel()
+			}
+
+			waitFor(t, "run finished", done)
+
+			if !tt.wantProbes && (liveness.wasStarted() || readiness.wasStarted()) {
+				t.Errorf("liveness or readiness started after the startup probe failed")
+			}
+			if got := handler.receivedSignals(); !equalSignals(got, tt.wantSignals) {
+				t.Errorf("signals = %v, want %v", got, tt.wantSignals)
+			}
+			assertFailures(t, c.Report(), tt.wantFailures)
+		})
+	}
+}
+
+func TestConductor_Run_listener(t *testing.T) {
+	t.Run("ok_probes_wait_for_listener", func(t *testing.T) {
+		addr := freeAddr(t)
+		handler := newFakeHandler()
+		c := newTestConductor(t, handler, addr)
+		liveness, readiness := c.livenessProbe.(*fakeProbe), c.readinessProbe.(*fakeProbe)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Run(ctx)
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		if liveness.wasStarted() || readiness.wasStarted() {
+			t.Fatalf("probes started before the service listened")
+		}
+
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Fatalf("failed to listen: %s", err)
+		}
+		serve(t, l)
+
+		waitFor(t, "liveness probe started", liveness.started)
+		waitFor(t, "readiness probe started", readiness.started)
+
+		cancel()
+		waitFor(t, "run finished", done)
+
+		assertFailures(t, c.Report(), nil)
+	})
+
+	t.Run("err_listen_timeout", func(t *testing.T) {
+		handler := newFakeHandler()
+		c := newTestConductor(t, handler, freeAddr(t))
+		c.listenTimeout = 100 * time.Millisecond
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Run(context.Background())
+		}()
+
+		waitFor(t, "run finished", done)
+
+		if c.livenessProbe.(*fakeProbe).wasStarted() {
+			t.Errorf("probes started although the service did not listen")
+		}
+		if got, want := handler.receivedSignals(), []process.Signal{process.SignalKill}; !equalSignals(got, want) {
+			t.Errorf("signals = %v, want %v", got, want)
+		}
+		// aborting the run is not a kill during the shutdown
+		if _, _, killed := c.Report().Kill(); killed {
+			t.Errorf("Report().Kill() killed = true, want the abort not recorded as kill during the shutdown")
+		}
+		if tl := c.Report().buildTimeline().String(); !strings.Contains(tl, "sent SIGKILL") {
+			t.Errorf("timeline does not contain the kill:\n%s", tl)
+		}
+		assertFailures(t, c.Report(), []string{"service did not listen on"})
+	})
+}
+
+func TestConductor_initiateShutdown_sequence(t *testing.T) {
+	sequence := []terminationStep{
+		{signal: process.SignalInterrupt, wait: 100 * time.Millisecond},
+		{signal: process.SignalTerminate, wait: 200 * time.Millisecond},
+		{signal: process.SignalKill},
+	}
+
+	tests := []struct {
+		name        string
+		exitOn      []process.Signal
+		wantSignals []process.Signal
+		wantKilled  bool
+	}{
+		{
+			name:        "ok_exit_on_first_signal",
+			exitOn:      []process.Signal{process.SignalInterrupt},
+			wantSignals: []process.Signal{process.SignalInterrupt},
+		},
+		{
+			name:        "ok_exit_after_escalation",
+			exitOn:      []process.Signal{process.SignalTerminate},
+			wantSignals: []process.Signal{process.SignalInterrupt, process.SignalTerminate},
+		},
+		{
+			name:        "err_killed",
+			wantSignals: []process.Signal{process.SignalInterrupt, process.SignalTerminate, process.SignalKill},
+			wantKilled:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newFakeHandler(tt.exitOn...)
+			c := newTestConductor(t, handler, listen(t))
+			c.terminationSequence = sequence
+
+			startFakeHandler(t, handler)
+			c.initiateShutdown()
+
+			signals := handler.receivedSignalsWithTime()
+			got := make([]process.Signal, len(signals))
+			for i, s := range signals {
+				got[i] = s.signal
+			}
+			if !equalSignals(got, tt.wantSignals) {
+				t.Fatalf("signals = %v, want %v", got, tt.wantSignals)
+			}
+
+			// every escalation happens after the wait of the previous step
+			for i := 1; i < len(signals); i++ {
+				wait := sequence[i-1].wait
+				if elapsed := signals[i].time.Sub(signals[i-1].time); elapsed < wait || elapsed > wait+500*time.Millisecond {
+					t.Errorf("%s sent %s after %s, want %s", signals[i].signal, elapsed, signals[i-1].signal, wait)
+				}
+			}
+
+			if _, _, killed := c.Report().Kill(); killed != tt.wantKilled {
+				t.Errorf("Report().Kill() killed = %t, want %t", killed, tt.wantKilled)
+			}
+		})
+	}
+}
+
+// startFakeHandler runs the fake process until the test finished.
+func startFakeHandler(t *testing.T, handler *fakeHandler) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go handler.Start(ctx)
+}
+
+func Test_assessListenerOwnership(t *testing.T) {
+	descendants := []process.Descendant{
+		{PID: 101, PPID: 100, Command: "node", AliveAtKill: true},
+		{PID: 102, PPID: 100, Command: "java"},
+	}
+
+	tests := []struct {
+		name         string
+		owners       []int
+		notStarted   bool
+		signalGroup  bool
+		wantProblems []string
+		wantNotes    []string
+	}{
+		{name: "ok_no_owners"},
+		{name: "ok_not_started", owners: []int{300}, notStarted: true},
+		{name: "ok_signalled_owner", owners: []int{100}},
+		{name: "ok_signalled_owner_among_others", owners: []int{100, 101}},
+		{name: "ok_group_signalled", owners: []int{101}, signalGroup: true},
+		{
+			name:      "ok_descendant_exited_on_its_own",
+			owners:    []int{102},
+			wantNotes: []string{"pid=102 (java), which exited on its own after the termination signal was sent to pid=100"},
+		},
+		{
+			name:         "err_not_forwarded_until_kill",
+			owners:       []int{101},
+			wantProblems: []string{"pid=101 (node), but the termination signal was sent to pid=100 which did not forward it"},
+		},
+		{
+			name:         "err_not_a_descendant",
+			owners:       []int{300},
+			signalGroup:  true,
+			wantProblems: []string{"pid=300, which is not a descendant of the signalled pid=100"},
+		},
+		{
+			name:   "err_every_owner",
+			owners: []int{101, 102, 300},
+			wantProblems: []string{
+				"pid=101 (node), but the termination signal was sent to pid=100 which did not forward it",
+				"pid=300, which is not a descendant of the signalled pid=100",
+			},
+			wantNotes: []string{"pid=102 (java), which exited on its own"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pid := 100
+			if tt.notStarted {
+				pid = 0
+			}
+
+			problems, notes := assessListenerOwnership(8080, pid, tt.owners, descendants, tt.signalGroup)
+			assertContainsEach(t, "problems", problems, tt.wantProblems)
+			assertContainsEach(t, "notes", notes, tt.wantNotes)
+		})
+	}
+}
+
+func assertContainsEach(t *testing.T, kind string, got, want []string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("assessListenerOwnership() %s = %q, want %d", kind, got, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(got[i], w) {
+			t.Errorf("assessListenerOwnership() %s[%d] = %q, want it to contain %q", kind, i, got[i], w)
+		}
+	}
+}
+
+func TestConductor_initiateShutdown_gracePeriod(t *testing.T) {
+	tests := []struct {
+		name        string
+		hook        time.Duration
+		gracePeriod time.Duration
+		exitOn      []process.Signal
+		wantSignals []process.Signal
+		// wantAfter is the time after the start of the shutdown each signal is expected at
+		wantAfter    []time.Duration
+		wantFailures []string
+	}{
+		{
+			name:        "ok_exit_within_grace_period",
+			hook:        100 * time.Millisecond,
+			gracePeriod: time.Second,
+			exitOn:      []process.Signal{process.SignalTerminate},
+			wantSignals: []process.Signal{process.SignalTerminate},
+			wantAfter:   []time.Duration{100 * time.Millisecond},
+		},
+		{
+			name:        "err_killed_after_grace_period",
+			hook:        100 * time.Millisecond,
+			gracePeriod: 2500 * time.Millisecond,
+			wantSignals: []process.Signal{process.SignalTerminate, process.SignalKill},
+			wantAfter:   []time.Duration{100 * time.Millisecond, 2500 * time.Millisecond},
+		},
+		{
+			name:        "err_hook_exceeds_grace_period",
+			hook:        5 * time.Second,
+			gracePeriod: 200 * time.Millisecond,
+			wantSignals: []process.Signal{process.SignalTerminate, process.SignalKill},
+			// the process gets the minimum grace period after the aborted hook
+			wantAfter:    []time.Duration{200 * time.Millisecond, 200*time.Millisecond + minimumGracePeriod},
+			wantFailures: []string{"did not finish within the termination grace period"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newFakeHandler(tt.exitOn...)
+			c := newTestConductor(t, handler, listen(t))
+			c.gracePeriod = tt.gracePeriod
+			c.preStop = &fakeHook{duration: tt.hook}
+
+			startFakeHandler(t, handler)
+
+			start := time.Now()
+			c.initiateShutdown()
+
+			signals := handler.receivedSignalsWithTime()
+			if len(signals) != len(tt.wantSignals) {
+				t.Fatalf("signals = %v, want %v", handler.receivedSignals(), tt.wantSignals)
+			}
+			for i, s := range signals {
+				after := s.time.Sub(start)
+				if s.signal != tt.wantSignals[i] || after < tt.wantAfter[i] || after > tt.wantAfter[i]+300*time.Millisecond {
+					t.Errorf("sent %s after %s, want %s after %s", s.signal, after, tt.wantSignals[i], tt.wantAfter[i])
+				}
+			}
+
+			assertFailures(t, c.Report(), tt.wantFailures)
+		})
+	}
+}
+
+// newTestConductor returns a conductor for the fake handler whose traffic target is addr.
+// Probes are started without delay once the listener accepts connections.
+func newTestConductor(t *testing.T, handler *fakeHandler, addr string) *Conductor {
+	t.Helper()
+
+	simulator := &fakeSimulator{report: traffic.NewSimulationReport()}
+
+	return &Conductor{
+		listenerAddr:        addr,
+		listener:            listener.NewWatcher(addr, listenerCheckInterval),
+		listenTimeout:       5 * time.Second,
+		gracefulExitCodes:   []int{0, 143},
+		terminationSequence: []terminationStep{{signal: process.SignalTerminate}},
+		gracePeriod:         30 * time.Second,
+		processHandler:      handler,
+		livenessProbe:       newFakeProbe(),
+		readinessProbe:      newFakeProbe(),
+		traffic:             simulator,
+		report:              NewReport(simulator.Report(), handler.Report()),
+	}
+}
+
+// listen returns the address of a listener which is closed when the test finished.
+func listen(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	serve(t, l)
+
+	return l.Addr().String()
+}
+
+// serve accepts connections until the test finished.
+func serve(t *testing.T, l net.Listener) {
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+}
+
+// freeAddr returns an address nothing listens on.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	defer l.Close()
+
+	return l.Addr().String()
+}
+
+func waitFor(t *testing.T, what string, ch <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s: timed out", what)
+	}
+}
+
+// assertFailures checks the failures of the run, each has to contain the wanted text at the same position.
+func assertFailures(t *testing.T, r *Report, want []string) {
+	t.Helper()
+
+	r.mu.RLock()
+	failures := append([]string{}, r.failures...)
+	r.mu.RUnlock()
+
+	if len(failures) != len(want) {
+		t.Errorf("failures = %q, want %q", failures, want)
+		return
+	}
+
+	for i, w := range want {
+		if !strings.Contains(failures[i], w) {
+			t.Errorf("failures[%d] = %q, want it to contain %q", i, failures[i], w)
+		}
+	}
+}
+
+func equalSignals(got, want []process.Signal) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+type receivedSignal struct {
+	signal process.Signal
+	time   time.Time
+}
+
+// fakeHandler is a process which runs until it receives SIGKILL or one of the signals in exitOn.
+type fakeHandler struct {
+	mu          sync.Mutex
+	subscribers []chan process.Status
+	states      []chan process.Notification
+	signals     []receivedSignal
+	exitOn      map[process.Signal]bool
+	exited      chan struct{}
+	exitOnce    sync.Once
+	report      *process.Report
+}
+
+var _ process.Handler = &fakeHandler{}
+
+func newFakeHandler(exitOn ...process.Signal) *fakeHandler {
+	h := &fakeHandler{
+		exitOn: make(map[process.Signal]bool),
+		exited: make(chan struct{}),
+		report: process.NewReport(),
+	}
+	for _, s := range exitOn {
+		h.exitOn[s] = true
+	}
+
+	return h
+}
+
+func (h *fakeHandler) Start(ctx context.Context) error {
+	h.setStatus(process.Running)
+
+	select {
+	case <-h.exited:
+	case <-ctx.Done():
+	}
+
+	h.setStatus(process.Exited)
+
+	h.mu.Lock()
+	for _, nCh := range h.states {
+		close(nCh)
+	}
+	h.states = nil
+	h.mu.Unlock()
+
+	return nil
+}
+
+func (h *fakeHandler) Signal(signal process.Signal) {
+	h.mu.Lock()
+	h.signals = append(h.signals, receivedSignal{signal: signal, time: time.Now()})
+	h.mu.Unlock()
+
+	if signal == process.SignalKill || h.exitOn[signal] {
+		h.exitOnce.Do(func() { close(h.exited) })
+	}
+}
+
+func (h *fakeHandler) Notify(sCh chan process.Status) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	queue := make(chan process.Status, 2)
+	go func() {
+		for status := range queue {
+			sCh <- status
+		}
+	}()
+
+	h.subscribers = append(h.subscribers, queue)
+}
+
+func (h *fakeHandler) NotifyOutput(oCh chan process.OutputLine) {}
+
+func (h *fakeHandler) NotifyState(nCh chan process.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.states = append(h.states, nCh)
+}
+
+func (h *fakeHandler) Report() *process.Report {
+	return h.report
+}
+
+func (h *fakeHandler) setStatus(status process.Status) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, queue := range h.subscribers {
+		queue <- status
+	}
+}
+
+func (h *fakeHandler) receivedSignalsWithTime() []receivedSignal {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]receivedSignal{}, h.signals...)
+}
+
+func (h *fakeHandler) receivedSignals() []process.Signal {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	signals := make([]process.Signal, len(h.signals))
+	for i, s := range h.signals {
+		signals[i] = s.signal
+	}
+
+	return signals
+}
+
+// fakeProbe reports the statuses set by the test and a fixed history.
+type fakeProbe struct {
+	mu          sync.Mutex
+	started     chan struct{}
+	startOnce   sync.Once
+	subscribers []chan probe.Status
+	history     []probe.Result
+}
+
+var _ probe.Interface = &fakeProbe{}
+
+func newFakeProbe() *fakeProbe {
+	return &fakeProbe{started: make(chan struct{})}
+}
+
+func (p *fakeProbe) Run(ctx context.Context) {
+	p.startOnce.Do(func() { close(p.started) })
+	<-ctx.Done()
+}
+
+func (p *fakeProbe) Check() error {
+	return nil
+}
+
+func (p *fakeProbe) Notify(sCh chan probe.Status) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.subscribers = append(p.subscribers, sCh)
+}
+
+func (p *fakeProbe) Stats() probe.Stats {
+	return probe.Stats{Checks: len(p.history)}
+}
+
+func (p *fakeProbe) History() []probe.Result {
+	return p.history
+}
+
+func (p *fakeProbe) wasStarted() bool {
+	select {
+	case <-p.started:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *fakeProbe) set(t *testing.T, status probe.Status) {
+	t.Helper()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, sCh := range p.subscribers {
+		select {
+		case sCh <- status:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("status %s not consumed", status)
+		}
+	}
+}
+
+// fakeHook takes the given duration unless its context is done before.
+type fakeHook struct {
+	duration time.Duration
+}
+
+func (h *fakeHook) Run(ctx context.Context) error {
+	select {
+	case <-time.After(h.duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *fakeHook) String() string {
+	return fmt.Sprintf("sleep %s", h.duration)
+}
+
+// fakeSimulator does not send any traffic.
+type fakeSimulator struct {
+	report *traffic.SimulationReport
+}
+
+func (s *fakeSimulator) Report() *traffic.SimulationReport {
+	return s.report
+}
+
+func (s *fakeSimulator) Simulate(ctx context.Context, group *sync.WaitGroup) {}
@@ -0,0 +1,82 @@
+//go:build !windows
+// +build !windows
+
+package grace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/process"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/traffic"
+)
+
+func TestConductor_evaluateKill(t *testing.T) {
+	tests := []struct {
+		name   string
+		killed bool
+		// killAt is the time of SIGKILL relative to the exit of the process
+		killAt       time.Duration
+		wantFailures []string
+	}{
+		{name: "ok_not_killed"},
+		{name: "ok_exited_before_kill", killed: true, killAt: 100 * time.Millisecond},
+		{
+			name:         "err_killed",
+			killed:       true,
+			killAt:       -100 * time.Millisecond,
+			wantFailures: []string{"was killed 900ms after the shutdown started, 2 traffic requests were in flight"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := process.NewHandler("/bin/sh", "-c", "exit 0")
+			if err := handler.Start(context.Background()); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			exit, ok := handler.Report().Exit()
+			if !ok {
+				t.Fatalf("Report().Exit() ok = false")
+			}
+
+			c := &Conductor{
+				processHandler: handler,
+				report:         NewReport(traffic.NewSimulationReport(), handler.Report()),
+			}
+
+			c.report.RecordSignal(process.SignalTerminate, exit.Time.Add(-time.Second))
+			if tt.killed {
+				c.report.RecordKill(exit.Time.Add(tt.killAt), 2)
+			}
+
+			c.evaluateKill()
+
+			assertFailures(t, c.Report(), tt.wantFailures)
+		})
+	}
+}
+
+func TestConductor_Run_listenTimeout(t *testing.T) {
+	handler := process.NewHandler("/bin/sh", "-c", "sleep 5")
+	c := newTestConductor(t, newFakeHandler(), freeAddr(t))
+	c.processHandler = handler
+	c.report = NewReport(c.traffic.Report(), handler.Report())
+	c.listenTimeout = 100 * time.Millisecond
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(context.Background())
+	}()
+
+	waitFor(t, "run finished", done)
+
+	exit, ok := handler.Report().Exit()
+	if !ok || !exit.Killed {
+		t.Fatalf("Report().Exit() = %+v, %t, want the process killed", exit, ok)
+	}
+
+	// neither the kill nor the exit code caused by it fail the run a second time
+	assertFailures(t, c.Report(), []string{"service did not listen on"})
+}
@@ -0,0 +1,186 @@
+package grace
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/probe"
+	"github.com/pkg/errors"
+)
+
+// preflightDialTimeout is the time to wait for a connection to a target port which is expected to be free.
+const preflightDialTimeout = 200 * time.Millisecond
+
+// PreflightError lists all problems found before the process was started.
+type PreflightError struct {
+	Problems []string
+}
+
+func (e *PreflightError) Error() string {
+	return fmt.Sprintf("pre-flight checks failed:\n\t%s", strings.Join(e.Problems, "\n\t"))
+}
+
+// Preflight checks the setup of the run before anything is started, so a misconfiguration does not
+// lead to a misleading result. All problems found are returned in a single *PreflightError.
+func (c *Conductor) Preflight() error {
+	problems := make([]string, 0)
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	cfg := c.config
+
+	for _, p := range c.probes() {
+		if err := p.probe.Check(); err != nil {
+			add("%s probe: %s", p.kind, err)
+		}
+	}
+
+	if err := checkHTTPTarget(cfg.Traffic.Target.Val); err != nil {
+		add("traffic target: %s", err)
+	}
+
+	for _, p := range c.probeConfigs() {
+		if err := checkProbeTarget(p.config); err != nil {
+			add("%s probe target: %s", p.kind, err)
+		}
+	}
+
+	if cfg.Process.PreStop.Type == options.HookTypeHTTP {
+		if err := checkHTTPTarget(cfg.Process.PreStop.Target.Val); err != nil {
+			add("pre stop hook target: %s", err)
+		}
+	}
+
+	// an attached process is expected to be running already
+	if cfg.Process.PID == 0 {
+		if err := checkCommand(cfg.Process.Command, cfg.Process.WorkingDir); err != nil {
+			add("%s", err)
+		}
+
+		for _, addr := range c.targetAddresses() {
+			conn, err := net.DialTimeout("tcp", addr, preflightDialTimeout)
+			if err == nil {
+				conn.Close()
+				add("target %s is already in use, traffic and probes would hit another server", addr)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &PreflightError{Problems: problems}
+	}
+
+	return nil
+}
+
+type kindProbe struct {
+	kind  string
+	probe probe.Interface
+}
+
+func (c *Conductor) probes() []kindProbe {
+	probes := make([]kindProbe, 0, 3)
+	if c.startupProbe != nil {
+		probes = append(probes, kindProbe{kind: "startup", probe: c.startupProbe})
+	}
+
+	return append(probes,
+		kindProbe{kind: "liveness", probe: c.livenessProbe},
+		kindProbe{kind: "readiness", probe: c.readinessProbe},
+	)
+}
+
+type kindProbeConfig struct {
+	kind   string
+	config options.ProbeConfig
+}
+
+func (c *Conductor) probeConfigs() []kindProbeConfig {
+	return []kindProbeConfig{
+		{kind: "startup", config: c.config.StartupProbe},
+		{kind: "liveness", config: c.config.LivenessProbe},
+		{kind: "readiness", config: c.config.ReadinessProbe},
+	}
+}
+
+// targetAddresses returns the distinct addresses the traffic and the network probes are sent to.
+func (c *Conductor) targetAddresses() []string {
+	targets := []url.URL{c.config.Traffic.Target.Val}
+	for _, p := range c.probeConfigs() {
+		switch p.config.Type {
+		case options.ProbeTypeHTTP, options.ProbeTypeTCP, options.ProbeTypeGRPC:
+			targets = append(targets, p.config.Target.Val)
+		}
+	}
+
+	seen := make(map[string]bool)
+	addresses := make([]string, 0, len(targets))
+	for _, u := range targets {
+		addr := u.Host
+		if len(u.Port()) == 0 {
+			continue
+		}
+		if !seen[addr] {
+			seen[addr] = true
+			addresses = append(addresses, addr)
+		}
+	}
+
+	return addresses
+}
+
+func checkProbeTarget(cfg options.ProbeConfig) error {
+	switch cfg.Type {
+	case options.ProbeTypeHTTP:
+		return checkHTTPTarget(cfg.Target.Val)
+	case options.ProbeTypeTCP, options.ProbeTypeGRPC:
+		if _, _, err := net.SplitHostPort(cfg.Target.Val.Host); err != nil {
+			return errors.Wrapf(err, "%s requires host and port", cfg.Target.String())
+		}
+	}
+
+	return nil
+}
+
+func checkHTTPTarget(u url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Errorf("%s must use the http or https scheme", u.String())
+	}
+
+	if len(u.Host) == 0 {
+		return errors.Errorf("%s is missing a host", u.String())
+	}
+
+	return nil
+}
+
+// checkCommand verifies the command can be executed from the working directory.
+func checkCommand(command, dir string) error {
+	if len(dir) != 0 {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return errors.Wrap(err, "working directory")
+		}
+		if !info.IsDir() {
+			return errors.Errorf("working directory %s is not a directory", dir)
+		}
+
+		if strings.Contains(command, string(filepath.Separator)) && !filepath.IsAbs(command) {
+			command = filepath.Join(dir, command)
+		}
+	}
+
+	if _, err := exec.LookPath(command); err != nil {
+		return errors.Wrap(err, "command is not executable")
+	}
+
+	return nil
+}
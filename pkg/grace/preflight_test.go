@@ -0,0 +1,91 @@
+package grace
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+)
+
+func TestConductor_Preflight(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	defer occupied.Close()
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	freeAddr := free.Addr().String()
+	free.Close()
+
+	tests := []struct {
+		name         string
+		configure    func(cfg *options.Config)
+		wantProblems []string
+	}{
+		{
+			name:         "ok",
+			configure:    func(cfg *options.Config) {},
+			wantProblems: nil,
+		},
+		{
+			name: "err_all_problems",
+			configure: func(cfg *options.Config) {
+				cfg.Process.Command = "/nonexistent/server"
+				cfg.Traffic.Target.Val.Host = occupied.Addr().String()
+				cfg.LivenessProbe.RequestTimeout = time.Minute
+				cfg.ReadinessProbe.FailureThreshold = 0
+				cfg.ReadinessProbe.Target.Val.Scheme = "ftp"
+			},
+			wantProblems: []string{
+				"liveness probe: probe timeout",
+				"readiness probe: probe thresholds must be positive",
+				"readiness probe target: ",
+				"command is not executable",
+				"target " + occupied.Addr().String() + " is already in use",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := options.NewConfigWithDefaults()
+			cfg.Process.Command = "/bin/sh"
+			cfg.Traffic.Target.Val = url.URL{Scheme: "http", Host: freeAddr, Path: "/"}
+			cfg.LivenessProbe.Target.Val = url.URL{Scheme: "http", Host: freeAddr, Path: "/health"}
+			cfg.ReadinessProbe.Target.Val = url.URL{Scheme: "http", Host: freeAddr, Path: "/health/readiness"}
+			tt.configure(cfg)
+
+			c, err := NewConductor(cfg)
+			if err != nil {
+				t.Fatalf("NewConductor() error = %v", err)
+			}
+
+			err = c.Preflight()
+			if len(tt.wantProblems) == 0 {
+				if err != nil {
+					t.Errorf("Preflight() error = %v, want nil", err)
+				}
+				return
+			}
+
+			pe, ok := err.(*PreflightError)
+			if !ok {
+				t.Fatalf("Preflight() error = %v, want *PreflightError", err)
+			}
+			if len(pe.Problems) != len(tt.wantProblems) {
+				t.Fatalf("Preflight() problems = %q, want %d", pe.Problems, len(tt.wantProblems))
+			}
+			for n, want := range tt.wantProblems {
+				if !strings.HasPrefix(pe.Problems[n], want) {
+					t.Errorf("Preflight() problem %d = %q, want prefix %q", n, pe.Problems[n], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package grace
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/probe"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/process"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/timeline"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/traffic"
+)
+
+func NewReport(traffic *traffic.SimulationReport, process *process.Report) *Report {
+	return &Report{
+		traffic:  traffic,
+		process:  process,
+		probes:   make([]reportedProbe, 0),
+		failures: make([]string, 0),
+		notes:    make([]string, 0),
+		signals:  make([]sentSignal, 0),
+		timeline: timeline.New(),
+	}
+}
+
+// Report combines the results of a run into a single verdict. Events of all parts of the run are
+// ordered in a single timeline.
+type Report struct {
+	mu       sync.RWMutex
+	traffic  *traffic.SimulationReport
+	process  *process.Report
+	probes   []reportedProbe
+	failures []string
+	notes    []string
+	signals  []sentSignal
+	timings  ShutdownTimings
+	listener listenerOwnership
+	preStop  *preStopRun
+	// inFlightAtKill is the number of traffic requests not finished when SIGKILL was sent.
+	inFlightAtKill int
+	timeline       *timeline.Timeline
+}
+
+// preStopRun is the execution of the pre stop hook.
+type preStopRun struct {
+	hook     string
+	started  time.Time
+	finished time.Time
+	err      error
+}
+
+type listenerOwnership struct {
+	port   int
+	owners []int
+}
+
+type sentSignal struct {
+	signal process.Signal
+	time   time.Time
+}
+
+// ShutdownTimings are the moments relevant to the shutdown of the service. Zero values were not observed.
+type ShutdownTimings struct {
+	// Started is the start of the pre stop hook or, without hook, the first signal.
+	Started         time.Time
+	Signaled        time.Time
+	ReadinessFailed time.Time
+	ListenerClosed  time.Time
+	Exited          time.Time
+	Killed          time.Time
+}
+
+// ReadinessLag is the duration between the start of the shutdown and the readiness probe turning red. It is measured
+// from the pre stop hook, if any, as the hook commonly makes the service report it is not ready.
+func (st ShutdownTimings) ReadinessLag() (time.Duration, bool) {
+	if st.Started.IsZero() || st.ReadinessFailed.IsZero() {
+		return 0, false
+	}
+
+	return st.ReadinessFailed.Sub(st.Started), true
+}
+
+// ListenerCloseTime is the duration between the termination signal and the listener refusing connections.
+func (st ShutdownTimings) ListenerCloseTime() (time.Duration, bool) {
+	return st.since(st.ListenerClosed)
+}
+
+// ExitTime is the duration between the termination signal and the exit of the process.
+func (st ShutdownTimings) ExitTime() (time.Duration, bool) {
+	return st.since(st.Exited)
+}
+
+// KillTime is the duration between the termination signal and SIGKILL.
+func (st ShutdownTimings) KillTime() (time.Duration, bool) {
+	return st.since(st.Killed)
+}
+
+func (st ShutdownTimings) since(t time.Time) (time.Duration, bool) {
+	if st.Signaled.IsZero() || t.IsZero() {
+		return 0, false
+	}
+
+	return t.Sub(st.Signaled), true
+}
+
+type reportedProbe struct {
+	kind  string
+	probe probe.Interface
+}
+
+// AddProbe includes the statistics of the probe of the given kind in the report.
+func (r *Report) AddProbe(kind string, p probe.Interface) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.probes = append(r.probes, reportedProbe{kind: kind, probe: p})
+}
+
+// Fail records a reason why the run failed, independent of the traffic results.
+func (r *Report) Fail(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// Note records an observation of the run which does not fail it.
+func (r *Report) Note(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notes = append(r.notes, fmt.Sprintf(format, args...))
+}
+
+// RecordSignal records a signal sent to the process. The first one marks the start of the shutdown.
+func (r *Report) RecordSignal(signal process.Signal, t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timings.Signaled.IsZero() {
+		r.timings.Signaled = t
+	}
+	if r.timings.Started.IsZero() {
+		r.timings.Started = t
+	}
+
+	r.signals = append(r.signals, sentSignal{signal: signal, time: t})
+	r.timeline.Add(t, "signal", "sent %s", signal)
+}
+
+// RecordPreStopStarted records the start of the pre stop hook, which marks the start of the shutdown.
+func (r *Report) RecordPreStopStarted(hook string, t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.preStop = &preStopRun{hook: hook, started: t}
+	if r.timings.Started.IsZero() {
+		r.timings.Started = t
+	}
+	r.timeline.Add(t, "preStop", "%s started", hook)
+}
+
+func (r *Report) RecordPreStopFinished(t time.Time, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.preStop == nil {
+		return
+	}
+
+	r.preStop.finished = t
+	r.preStop.err = err
+
+	if err != nil {
+		r.timeline.Add(t, "preStop", "failed after %s: %s", t.Sub(r.preStop.started).Round(time.Millisecond), err)
+	} else {
+		r.timeline.Add(t, "preStop", "finished after %s", t.Sub(r.preStop.started).Round(time.Millisecond))
+	}
+}
+
+// RecordReadinessFailure records the first readiness failure after the shutdown started.
+func (r *Report) RecordReadinessFailure(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.timings.Started.IsZero() && r.timings.ReadinessFailed.IsZero() {
+		r.timings.ReadinessFailed = t
+	}
+}
+
+// RecordListenerClosed records the listener refusing connections. The first time after the shutdown started
+// marks the close of the listener.
+func (r *Report) RecordListenerClosed(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.timeline.Add(t, "listener", "refused connection")
+
+	if !r.timings.Started.IsZero() && r.timings.ListenerClosed.IsZero() {
+		r.timings.ListenerClosed = t
+	}
+}
+
+// RecordKill records the SIGKILL of the process during the shutdown and the traffic requests in flight at that time.
+func (r *Report) RecordKill(t time.Time, inFlight int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timings.Killed.IsZero() {
+		r.timings.Killed = t
+		r.inFlightAtKill = inFlight
+		r.timeline.Add(t, "signal", "killed with %d traffic requests in flight", inFlight)
+	}
+}
+
+// Kill returns the time of SIGKILL during the shutdown and the traffic requests in flight at that time.
+func (r *Report) Kill() (time.Time, int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.timings.Killed, r.inFlightAtKill, !r.timings.Killed.IsZero()
+}
+
+func (r *Report) RecordExit(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timings.Exited.IsZero() {
+		r.timings.Exited = t
+	}
+}
+
+// RecordEvent adds an event of the run to the timeline.
+func (r *Report) RecordEvent(t time.Time, source, format string, args ...interface{}) {
+	r.timeline.Add(t, source, format, args...)
+}
+
+func (r *Report) RecordListenerOwners(port int, owners []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.listener = listenerOwnership{port: port, owners: owners}
+}
+
+func (r *Report) ListenerOwners() (int, []int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.listener.port, r.listener.owners
+}
+
+// ShutdownStarted returns the start of the pre stop hook or, without hook, the time of the first signal.
+func (r *Report) ShutdownStarted() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.timings.Started
+}
+
+func (r *Report) Timings() ShutdownTimings {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.timings
+}
+
+func (r *Report) String() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	buf := bytes.NewBuffer([]byte(""))
+
+	fmt.Fprint(buf, r.traffic.String())
+
+	fmt.Fprint(buf, "\n")
+
+	if len(r.probes) > 0 {
+		fmt.Fprint(buf, "probes:\n")
+		for _, rp := range r.probes {
+			stats := rp.probe.Stats()
+			fmt.Fprintf(buf, "\t%s: %d checks, %d skipped ticks\n", rp.kind, stats.Checks, stats.Skipped)
+			for _, result := range rp.probe.History() {
+				fmt.Fprintf(buf, "\t\t%s\n", formatResult(result))
+			}
+		}
+
+		fmt.Fprint(buf, "\n")
+	}
+
+	if r.preStop != nil {
+		fmt.Fprintf(buf, "pre stop hook %s started at %s:\n", r.preStop.hook, r.preStop.started.Format(timeline.TimeFormat))
+		switch {
+		case r.preStop.finished.IsZero():
+			fmt.Fprint(buf, "\tdid not finish\n")
+		case r.preStop.err != nil:
+			fmt.Fprintf(buf, "\tfailed after %s: %s\n", r.preStop.finished.Sub(r.preStop.started).Round(time.Millisecond), r.preStop.err)
+		default:
+			fmt.Fprintf(buf, "\tsucceeded after %s\n", r.preStop.finished.Sub(r.preStop.started).Round(time.Millisecond))
+		}
+
+		fmt.Fprint(buf, "\n")
+	}
+
+	if !r.timings.Signaled.IsZero() {
+		fmt.Fprintf(buf, "shutdown (signaled at %s):\n", r.timings.Signaled.Format(timeline.TimeFormat))
+		for _, s := range r.signals {
+			fmt.Fprintf(buf, "\tsent %s after %s\n", s.signal, s.time.Sub(r.timings.Signaled).Round(time.Millisecond))
+		}
+		fmt.Fprintf(buf, "\treadiness lag: %s\n", formatDuration(r.timings.ReadinessLag()))
+		fmt.Fprintf(buf, "\ttime to listener close: %s\n", formatDuration(r.timings.ListenerCloseTime()))
+		fmt.Fprintf(buf, "\ttime to process exit: %s\n", formatDuration(r.timings.ExitTime()))
+		if d, ok := r.timings.KillTime(); ok {
+			fmt.Fprintf(buf, "\tkilled after %s with %d traffic requests in flight\n", d.Round(time.Millisecond), r.inFlightAtKill)
+		} else {
+			fmt.Fprint(buf, "\tkilled: false\n")
+		}
+
+		fmt.Fprint(buf, "\n")
+	}
+
+	fmt.Fprint(buf, "timeline:\n")
+	for _, line := range strings.SplitAfter(r.buildTimeline().String(), "\n") {
+		if len(line) > 0 {
+			fmt.Fprintf(buf, "\t%s", line)
+		}
+	}
+
+	fmt.Fprint(buf, "\n")
+
+	fmt.Fprint(buf, r.process.String())
+
+	if len(r.listener.owners) > 0 {
+		fmt.Fprintf(buf, "listener on port %d owned by pid=%v, signals sent to pid=%d\n", r.listener.port, r.listener.owners, r.process.PID())
+	}
+
+	for _, note := range r.notes {
+		fmt.Fprintf(buf, "note: %s\n", note)
+	}
+
+	fmt.Fprint(buf, "\n")
+
+	for _, failure := range r.failures {
+		fmt.Fprintf(buf, "failure: %s\n", failure)
+	}
+
+	numErrors := r.traffic.NumErrors()
+	switch {
+	case len(r.failures) > 0:
+		fmt.Fprintf(buf, "GRACEFUL SHUTDOWN FAILED WITH %d FAILURES AND %d ERRORS!\n", len(r.failures), numErrors)
+	case numErrors > 0:
+		fmt.Fprintf(buf, "GRACEFUL SHUTDOWN FAILED WITH %d ERRORS!\n", numErrors)
+	default:
+		fmt.Fprint(buf, "GRACEFUL SHUTDOWN SUCCEED\n")
+	}
+
+	return buf.String()
+}
+
+// buildTimeline merges the recorded events with the output and notifications of the process,
+// failed probe checks and failed requests.
+func (r *Report) buildTimeline() *timeline.Timeline {
+	tl := timeline.New()
+	tl.Merge(r.timeline)
+
+	for _, line := range r.process.Output() {
+		tl.Add(line.Time, string(line.Stream), "%s", line.Text)
+	}
+
+	for _, n := range r.process.Notifications() {
+		tl.Add(n.Time, "sd_notify", "%s", n)
+	}
+
+	for _, rp := range r.probes {
+		for _, result := range rp.probe.History() {
+			if result.Err != nil {
+				tl.Add(result.Time, rp.kind, "check failed: %s", result.Err)
+			}
+		}
+	}
+
+	for _, e := range r.traffic.Errors() {
+		tl.Add(e.Time, "traffic", "request failed: %s", e.Err)
+	}
+
+	return tl
+}
+
+func formatDuration(d time.Duration, observed bool) string {
+	if !observed {
+		return "not observed"
+	}
+
+	return d.Round(time.Millisecond).String()
+}
+
+func formatResult(result probe.Result) string {
+	line := fmt.Sprintf(
+		"%s %s code=%d latency=%s",
+		result.Time.Format(timeline.TimeFormat),
+		result.Status,
+		result.StatusCode,
+		result.Latency.Round(time.Microsecond),
+	)
+
+	if result.Err != nil {
+		line += fmt.Sprintf(" error: %s", result.Err)
+	}
+
+	return line
+}
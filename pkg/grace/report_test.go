@@ -0,0 +1,156 @@
+package grace
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/probe"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/process"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/traffic"
+	"github.com/pkg/errors"
+)
+
+func TestReport_String_probeHistory(t *testing.T) {
+	start := time.Date(2021, 3, 4, 10, 0, 0, 0, time.Local)
+
+	readiness := newFakeProbe()
+	readiness.history = []probe.Result{
+		{Time: start, Latency: 2 * time.Millisecond, Status: probe.Success, StatusCode: 200},
+		{Time: start.Add(2 * time.Second), Latency: 3 * time.Millisecond, Status: probe.Failure, StatusCode: 503, Err: errors.New("unexpected status 503")},
+		{Time: start.Add(4 * time.Second), Status: probe.Failure, Err: errors.New("connection refused")},
+	}
+
+	r := NewReport(traffic.NewSimulationReport(), process.NewReport())
+	r.AddProbe("readiness", readiness)
+
+	want := []string{
+		"\treadiness: 3 checks, 0 skipped ticks\n",
+		"\t\t10:00:00.000 success code=200 latency=2ms\n",
+		"\t\t10:00:02.000 failure code=503 latency=3ms error: unexpected status 503\n",
+		"\t\t10:00:04.000 failure code=0 latency=0s error: connection refused\n",
+	}
+
+	got := r.String()
+	pos := 0
+	for _, line := range want {
+		n := strings.Index(got[pos:], line)
+		if n < 0 {
+			t.Fatalf("Report.String() does not contain %q after position %d:\n%s", line, pos, got)
+		}
+		pos += n + len(line)
+	}
+}
+
+func TestReport_RecordKill(t *testing.T) {
+	signaled := time.Date(2021, 3, 4, 10, 0, 0, 0, time.Local)
+
+	r := NewReport(traffic.NewSimulationReport(), process.NewReport())
+	r.RecordSignal(process.SignalTerminate, signaled)
+
+	if _, _, killed := r.Kill(); killed {
+		t.Fatalf("Report.Kill() killed = true before SIGKILL")
+	}
+	if got := r.String(); !strings.Contains(got, "\tkilled: false\n") {
+		t.Errorf("Report.String() does not report the process as not killed:\n%s", got)
+	}
+
+	r.RecordKill(signaled.Add(5*time.Second), 3)
+	// only the first SIGKILL is of interest
+	r.RecordKill(signaled.Add(6*time.Second), 1)
+
+	killedAt, inFlight, killed := r.Kill()
+	if !killed || !killedAt.Equal(signaled.Add(5*time.Second)) || inFlight != 3 {
+		t.Errorf("Report.Kill() = %s, %d, %t, want %s, 3, true", killedAt, inFlight, killed, signaled.Add(5*time.Second))
+	}
+	if d, ok := r.Timings().KillTime(); !ok || d != 5*time.Second {
+		t.Errorf("ShutdownTimings.KillTime() = %s, %t, want 5s, true", d, ok)
+	}
+
+	got := r.String()
+	for _, want := range []string{
+		"\tkilled after 5s with 3 traffic requests in flight\n",
+		"10:00:05.000 signal",
+		"killed with 3 traffic requests in flight\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Report.String() does not contain %q:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "killed"); n != 2 {
+		t.Errorf("Report.String() mentions the kill %d times, want once in the shutdown and once in the timeline:\n%s", n, got)
+	}
+}
+
+func TestReport_Note(t *testing.T) {
+	r := NewReport(traffic.NewSimulationReport(), process.NewReport())
+	r.Note("listener on port %d is owned by pid=%d", 8080, 102)
+
+	got := r.String()
+	if !strings.Contains(got, "note: listener on port 8080 is owned by pid=102\n") {
+		t.Errorf("Report.String() does not contain the note:\n%s", got)
+	}
+	if !strings.Contains(got, "GRACEFUL SHUTDOWN SUCCEED\n") {
+		t.Errorf("Report.String() does not succeed with a note only:\n%s", got)
+	}
+}
+
+func TestReport_ReadinessLag(t *testing.T) {
+	start := time.Date(2021, 3, 4, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name         string
+		record       func(r *Report)
+		wantLag      time.Duration
+		wantObserved bool
+	}{
+		{
+			name: "ok_without_hook",
+			record: func(r *Report) {
+				r.RecordSignal(process.SignalTerminate, start)
+				r.RecordReadinessFailure(start.Add(2 * time.Second))
+			},
+			wantLag:      2 * time.Second,
+			wantObserved: true,
+		},
+		{
+			// the hook makes the service report it is not ready before the termination signal is sent
+			name: "ok_during_hook",
+			record: func(r *Report) {
+				r.RecordPreStopStarted("http GET /drain", start)
+				r.RecordReadinessFailure(start.Add(time.Second))
+				r.RecordSignal(process.SignalTerminate, start.Add(3*time.Second))
+			},
+			wantLag:      time.Second,
+			wantObserved: true,
+		},
+		{
+			name: "ok_after_hook",
+			record: func(r *Report) {
+				r.RecordPreStopStarted("http GET /drain", start)
+				r.RecordSignal(process.SignalTerminate, start.Add(3*time.Second))
+				r.RecordReadinessFailure(start.Add(4 * time.Second))
+			},
+			wantLag:      4 * time.Second,
+			wantObserved: true,
+		},
+		{
+			name: "err_before_shutdown",
+			record: func(r *Report) {
+				r.RecordReadinessFailure(start.Add(-time.Second))
+				r.RecordSignal(process.SignalTerminate, start)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReport(traffic.NewSimulationReport(), process.NewReport())
+			tt.record(r)
+
+			lag, observed := r.Timings().ReadinessLag()
+			if observed != tt.wantObserved || lag != tt.wantLag {
+				t.Errorf("ShutdownTimings.ReadinessLag() = %s, %t, want %s, %t", lag, observed, tt.wantLag, tt.wantObserved)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/shell"
+	"github.com/pkg/errors"
+)
+
+func NewExecForConfig(cfg options.HookConfig) (*execHook, error) {
+	return NewExec(cfg.Command)
+}
+
+func NewExec(command string) (*execHook, error) {
+	if len(strings.TrimSpace(command)) == 0 {
+		return nil, errors.New("missing command for exec hook")
+	}
+
+	return &execHook{command: command}, nil
+}
+
+var _ Interface = &execHook{}
+
+// execHook runs a shell command and succeeds if it exits with code 0, like the kubernetes exec handler.
+type execHook struct {
+	command string
+}
+
+func (e *execHook) Run(ctx context.Context) error {
+	_, err := shell.Run(ctx, e.command)
+
+	return err
+}
+
+func (e *execHook) String() string {
+	return fmt.Sprintf("exec %q", e.command)
+}
@@ -0,0 +1,60 @@
+//go:build !windows
+// +build !windows
+
+package hook
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_execHook_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{name: "ok", command: "true", timeout: time.Second, wantErr: false},
+		{name: "err_exit_code", command: "echo draining failed; exit 1", timeout: time.Second, wantErr: true},
+		{name: "err_deadline", command: "sleep 5", timeout: 100 * time.Millisecond, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewExec(tt.command)
+			if err != nil {
+				t.Fatalf("NewExec() error = %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			start := time.Now()
+			if err := e.Run(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("execHook.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > tt.timeout+time.Second {
+				t.Errorf("execHook.Run() returned after %s, timeout was %s", elapsed, tt.timeout)
+			}
+		})
+	}
+}
+
+func TestNewExec(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		wantErr bool
+	}{
+		{name: "ok", command: "sleep 5", wantErr: false},
+		{name: "err_empty", command: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExec(tt.command); (err != nil) != tt.wantErr {
+				t.Errorf("NewExec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/http/transport"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/version"
+	"github.com/pkg/errors"
+)
+
+func NewHTTPForConfig(cfg options.HookConfig) (*httpHook, error) {
+	client := &http.Client{
+		Transport: &transport.UserAgent{
+			Transport: http.DefaultTransport,
+			UserAgent: fmt.Sprintf("%s/%s pre-stop-hook", options.ProjectName, version.GetInfo()),
+		},
+	}
+
+	u, err := url.Parse(cfg.Target.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse url %s", cfg.Target.String())
+	}
+
+	return NewHTTP(client, u)
+}
+
+func NewHTTP(client *http.Client, target *url.URL) (*httpHook, error) {
+	if len(target.Host) == 0 {
+		return nil, errors.Errorf("missing host in http hook target %s", target.String())
+	}
+
+	return &httpHook{client: client, target: target}, nil
+}
+
+var _ Interface = &httpHook{}
+
+// httpHook sends a GET request like the kubernetes httpGet handler. Status codes outside of 200-399 fail the hook.
+type httpHook struct {
+	client *http.Client
+	target *url.URL
+}
+
+func (h *httpHook) Run(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.target.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "request to %s failed", h.target.String())
+	}
+	defer res.Body.Close()
+
+	if _, err := io.Copy(ioutil.Discard, res.Body); err != nil {
+		return errors.Wrap(err, "failed to read body")
+	}
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("bad response code: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func (h *httpHook) String() string {
+	return fmt.Sprintf("httpGet %s", h.target.String())
+}
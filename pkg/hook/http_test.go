@@ -0,0 +1,49 @@
+package hook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_httpHook_Run(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{name: "ok", path: "/drain", timeout: time.Second, wantErr: false},
+		{name: "err_status_code", path: "/broken", timeout: time.Second, wantErr: true},
+		{name: "err_deadline", path: "/slow", timeout: 50 * time.Millisecond, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(srv.URL + tt.path)
+			h, err := NewHTTP(http.DefaultClient, u)
+			if err != nil {
+				t.Fatalf("NewHTTP() error = %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			if err := h.Run(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("httpHook.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package hook
+
+import (
+	"context"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/pkg/errors"
+)
+
+// Interface is a lifecycle handler of the process, like the kubernetes preStop hook.
+type Interface interface {
+	// Run executes the hook and blocks until it finished or the context is done.
+	Run(ctx context.Context) error
+	String() string
+}
+
+// NewForConfig creates the hook implementation selected by the type of the config.
+func NewForConfig(cfg options.HookConfig) (Interface, error) {
+	switch cfg.Type {
+	case options.HookTypeExec:
+		h, err := NewExecForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return h, nil
+	case options.HookTypeHTTP:
+		h, err := NewHTTPForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return h, nil
+	default:
+		return nil, errors.Errorf("unsupported hook type %q", cfg.Type)
+	}
+}
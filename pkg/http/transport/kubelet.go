@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// KubeletVersion is the kubernetes version announced in the User-Agent of kubelet compatible requests.
+const KubeletVersion = "1.21"
+
+// NewKubelet creates a transport sending requests the way kubelet http probes do: without keep-alive
+// and without verifying certificates.
+func NewKubelet() *Kubelet {
+	return &Kubelet{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		},
+		Version: KubeletVersion,
+	}
+}
+
+// Kubelet sets the default headers of kubelet http probes unless they are already set
+// and closes the connection after each request.
+type Kubelet struct {
+	Transport http.RoundTripper
+	Version   string
+}
+
+func (k *Kubelet) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(req.Header.Get("User-Agent")) == 0 {
+		req.Header.Set("User-Agent", fmt.Sprintf("kube-probe/%s", k.Version))
+	}
+
+	if len(req.Header.Get("Accept")) == 0 {
+		req.Header.Set("Accept", "*/*")
+	}
+
+	req.Header.Set("Connection", "close")
+	req.Close = true
+
+	return k.Transport.RoundTrip(req)
+}
@@ -0,0 +1,149 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	Listening Status = "listening"
+	Closed    Status = "closed"
+)
+
+// Event is a change of the listener status, timestamped when it was detected.
+type Event struct {
+	Time   time.Time
+	Status Status
+}
+
+func NewWatcher(addr string, interval time.Duration) *Watcher {
+	return &Watcher{
+		addr:        addr,
+		interval:    interval,
+		status:      Closed,
+		subscribers: make([]chan Event, 0),
+		detectClose: make(chan struct{}),
+	}
+}
+
+// Watcher detects whether a tcp listener accepts connections by connecting to it periodically.
+// The listener is considered closed until the first connection succeeded. To not interfere with the service,
+// connections are only attempted until it listens and, once requested by DetectClose, until it closed.
+type Watcher struct {
+	addr            string
+	interval        time.Duration
+	mu              sync.Mutex
+	status          Status
+	subscribers     []chan Event
+	detectClose     chan struct{}
+	detectCloseOnce sync.Once
+}
+
+// Notify subscribes to changes of the listener status. Subscribers must consume the events,
+// the channels are closed when Run returned.
+func (w *Watcher) Notify(eCh chan Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, eCh)
+}
+
+// Run watches the listener until it closed after DetectClose was called, or the context is done.
+func (w *Watcher) Run(ctx context.Context) {
+	defer w.closeSubscribers()
+
+	if !w.watch(ctx, Listening) {
+		return
+	}
+
+	select {
+	case <-w.detectClose:
+	case <-ctx.Done():
+		return
+	}
+
+	w.watch(ctx, Closed)
+}
+
+// DetectClose resumes connecting to the listener to detect when it stops accepting connections.
+func (w *Watcher) DetectClose() {
+	w.detectCloseOnce.Do(func() {
+		close(w.detectClose)
+	})
+}
+
+// watch connects to the listener periodically until it has the status. It returns false if the context is done before.
+func (w *Watcher) watch(ctx context.Context, until Status) bool {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if w.check(ctx) == until {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// check connects to the listener and returns its status.
+func (w *Watcher) check(ctx context.Context) Status {
+	dialCtx, cancel := context.WithTimeout(ctx, w.interval*10)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(dialCtx, "tcp", w.addr)
+	now := time.Now()
+
+	if ctx.Err() != nil {
+		return w.currentStatus()
+	}
+
+	if err != nil {
+		w.setStatus(now, Closed)
+		return Closed
+	}
+	conn.Close()
+
+	w.setStatus(now, Listening)
+	return Listening
+}
+
+func (w *Watcher) currentStatus() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.status
+}
+
+func (w *Watcher) setStatus(t time.Time, status Status) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.status == status {
+		return
+	}
+
+	w.status = status
+	for _, eCh := range w.subscribers {
+		eCh <- Event{Time: t, Status: status}
+	}
+}
+
+func (w *Watcher) closeSubscribers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, eCh := range w.subscribers {
+		close(eCh)
+	}
+	w.subscribers = nil
+}
@@ -0,0 +1,116 @@
+package listener
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Run(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	w := NewWatcher(addr, 10*time.Millisecond)
+	eCh := make(chan Event)
+	w.Notify(eCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go w.Run(ctx)
+
+	// nothing is listening yet, the watcher must not report a change
+	expectNoEvent(t, eCh)
+
+	l, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to listen again: %s", err)
+	}
+
+	var accepted int32
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&accepted, 1)
+			conn.Close()
+		}
+	}()
+
+	expectEvent(t, eCh, Listening)
+
+	// once listening, the watcher must not connect to the service anymore; the connection which detected the
+	// listener may still be accepted
+	time.Sleep(20 * time.Millisecond)
+	before := atomic.LoadInt32(&accepted)
+	time.Sleep(100 * time.Millisecond)
+	if after := atomic.LoadInt32(&accepted); after != before {
+		t.Errorf("watcher connected %d times after the listener was detected", after-before)
+	}
+
+	// the close is only detected once requested
+	l.Close()
+	expectNoEvent(t, eCh)
+
+	w.DetectClose()
+	expectEvent(t, eCh, Closed)
+
+	select {
+	case _, ok := <-eCh:
+		if ok {
+			t.Errorf("unexpected event after the listener closed")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("channel not closed after the listener closed")
+	}
+}
+
+func TestWatcher_Run_cancel(t *testing.T) {
+	w := NewWatcher("127.0.0.1:1", 10*time.Millisecond)
+	eCh := make(chan Event)
+	w.Notify(eCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go w.Run(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-eCh:
+		if ok {
+			t.Errorf("unexpected event after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("channel not closed after cancel")
+	}
+}
+
+func expectEvent(t *testing.T, eCh chan Event, want Status) {
+	t.Helper()
+
+	select {
+	case e := <-eCh:
+		if e.Status != want {
+			t.Fatalf("event = %s, want %s", e.Status, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("event %s not notified", want)
+	}
+}
+
+func expectNoEvent(t *testing.T, eCh chan Event) {
+	t.Helper()
+
+	select {
+	case e := <-eCh:
+		t.Fatalf("unexpected event %s", e.Status)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,137 @@
+package probe
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BodyMatcher asserts the response body of a http probe. A non-nil error fails the check.
+type BodyMatcher func(body []byte) error
+
+// NewRegexBodyMatcher creates a matcher which requires the body to match the regular expression expr.
+func NewRegexBodyMatcher(expr string) (BodyMatcher, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid body regex %q", expr)
+	}
+
+	return func(body []byte) error {
+		if !re.Match(body) {
+			return errors.Errorf("body does not match %q", expr)
+		}
+
+		return nil
+	}, nil
+}
+
+// NewJSONPathBodyMatcher creates a matcher which requires the body to be a json document
+// with value at path. The path is a dot separated list of keys and array indices,
+// e.g. $.components.db.status or checks[0].status. Strings are compared unquoted,
+// any other json value by its json representation.
+func NewJSONPathBodyMatcher(path, value string) (BodyMatcher, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(body []byte) error {
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return errors.Wrap(err, "body is not valid json")
+		}
+
+		actual, err := lookupJSONPath(doc, segments)
+		if err != nil {
+			return errors.Wrapf(err, "json path %s", path)
+		}
+
+		if actual != value {
+			return errors.Errorf("json path %s is %q, expected %q", path, actual, value)
+		}
+
+		return nil
+	}, nil
+}
+
+// jsonPathSegment is either a key of an object or an index of an array.
+type jsonPathSegment struct {
+	key   string
+	index int
+}
+
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if len(path) == 0 {
+		return nil, errors.New("empty json path")
+	}
+
+	segments := make([]jsonPathSegment, 0)
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		var indices []string
+		if pos := strings.Index(part, "["); pos >= 0 {
+			key = part[:pos]
+			if !strings.HasSuffix(part, "]") {
+				return nil, errors.Errorf("invalid json path segment %q", part)
+			}
+			indices = strings.Split(part[pos+1:len(part)-1], "][")
+		}
+
+		if len(key) != 0 {
+			segments = append(segments, jsonPathSegment{key: key, index: -1})
+		}
+
+		for _, idx := range indices {
+			n, err := strconv.Atoi(idx)
+			if err != nil || n < 0 {
+				return nil, errors.Errorf("invalid array index %q in json path segment %q", idx, part)
+			}
+			segments = append(segments, jsonPathSegment{index: n})
+		}
+	}
+
+	return segments, nil
+}
+
+func lookupJSONPath(doc interface{}, segments []jsonPathSegment) (string, error) {
+	current := doc
+
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if segment.index >= 0 {
+				return "", errors.Errorf("expected array at index %d, got object", segment.index)
+			}
+			value, ok := node[segment.key]
+			if !ok {
+				return "", errors.Errorf("key %q not found", segment.key)
+			}
+			current = value
+		case []interface{}:
+			if segment.index < 0 {
+				return "", errors.Errorf("expected object at key %q, got array", segment.key)
+			}
+			if segment.index >= len(node) {
+				return "", errors.Errorf("index %d out of range", segment.index)
+			}
+			current = node[segment.index]
+		default:
+			return "", errors.Errorf("cannot descend into scalar value")
+		}
+	}
+
+	if str, ok := current.(string); ok {
+		return str, nil
+	}
+
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
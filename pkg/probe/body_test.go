@@ -0,0 +1,60 @@
+package probe
+
+import "testing"
+
+func TestNewJSONPathBodyMatcher(t *testing.T) {
+	body := []byte(`{"status":"UP","components":{"db":{"status":"DOWN","details":{"pool":3}}},"checks":[{"ok":true}]}`)
+
+	type args struct {
+		path  string
+		value string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{name: "ok_root_key", args: args{path: "$.status", value: "UP"}, wantErr: false},
+		{name: "ok_without_prefix", args: args{path: "status", value: "UP"}, wantErr: false},
+		{name: "ok_nested", args: args{path: "$.components.db.status", value: "DOWN"}, wantErr: false},
+		{name: "ok_number", args: args{path: "components.db.details.pool", value: "3"}, wantErr: false},
+		{name: "ok_array_index", args: args{path: "checks[0].ok", value: "true"}, wantErr: false},
+		{name: "err_value_mismatch", args: args{path: "$.status", value: "DOWN"}, wantErr: true},
+		{name: "err_missing_key", args: args{path: "$.missing", value: "UP"}, wantErr: true},
+		{name: "err_index_out_of_range", args: args{path: "checks[1].ok", value: "true"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewJSONPathBodyMatcher(tt.args.path, tt.args.value)
+			if err != nil {
+				t.Fatalf("NewJSONPathBodyMatcher() error = %v", err)
+			}
+			if err := match(body); (err != nil) != tt.wantErr {
+				t.Errorf("match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRegexBodyMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		body    string
+		wantErr bool
+	}{
+		{name: "ok_match", expr: `"status"\s*:\s*"UP"`, body: `{"status": "UP"}`, wantErr: false},
+		{name: "err_no_match", expr: `"status"\s*:\s*"UP"`, body: `{"status": "DOWN"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := NewRegexBodyMatcher(tt.expr)
+			if err != nil {
+				t.Fatalf("NewRegexBodyMatcher() error = %v", err)
+			}
+			if err := match([]byte(tt.body)); (err != nil) != tt.wantErr {
+				t.Errorf("match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
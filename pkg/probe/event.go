@@ -0,0 +1,126 @@
+package probe
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+func newEventProbe(initialStatus Status) *eventProbe {
+	return &eventProbe{
+		status:      initialStatus,
+		subscribers: make([]chan Status, 0),
+		history:     make([]Result, 0),
+	}
+}
+
+// eventProbe holds the status of probes which are driven by events of the process instead of periodic checks.
+// Every event counts as check, events changing the status are kept in the history. Once the process reported
+// its shutdown, the probe fails for good. Events are observed independent of Run, so an event happening before
+// the probe was started is not missed.
+type eventProbe struct {
+	mu           sync.Mutex
+	status       Status
+	shuttingDown bool
+	closed       bool
+	subscribers  []chan Status
+	stats        Stats
+	history      []Result
+}
+
+// Run blocks until the context is done. Status changes are delivered to the subscribers until then.
+func (e *eventProbe) Run(ctx context.Context) {
+	<-ctx.Done()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.closed = true
+	for _, queue := range e.subscribers {
+		close(queue)
+	}
+	e.subscribers = nil
+}
+
+func (e *eventProbe) Check() error {
+	return nil
+}
+
+func (e *eventProbe) Notify(sCh chan Status) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return
+	}
+
+	// the status changes at most twice, to ready and to shutting down, so sending to the queue never blocks
+	queue := make(chan Status, 2)
+	go func() {
+		for status := range queue {
+			sCh <- status
+		}
+	}()
+
+	e.subscribers = append(e.subscribers, queue)
+}
+
+func (e *eventProbe) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.stats
+}
+
+func (e *eventProbe) History() []Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	history := make([]Result, len(e.history))
+	copy(history, e.history)
+
+	return history
+}
+
+func (e *eventProbe) observed() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stats.Checks++
+}
+
+func (e *eventProbe) ready(t time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.shuttingDown {
+		return
+	}
+
+	e.history = append(e.history, Result{Time: t, Status: Success})
+	e.setStatus(Success)
+}
+
+func (e *eventProbe) stopping(t time.Time, reason error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.shuttingDown = true
+	e.history = append(e.history, Result{Time: t, Status: Failure, Err: reason})
+	e.setStatus(Failure)
+}
+
+func (e *eventProbe) setStatus(status Status) {
+	if e.status == status {
+		return
+	}
+
+	e.status = status
+	if e.closed {
+		return
+	}
+
+	for _, queue := range e.subscribers {
+		queue <- status
+	}
+}
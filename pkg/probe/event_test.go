@@ -0,0 +1,66 @@
+package probe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_eventProbe_Notify(t *testing.T) {
+	e := newEventProbe(Failure)
+
+	sCh := make(chan Status)
+	e.Notify(sCh)
+
+	// the subscriber does not consume yet, no transition may be dropped
+	e.ready(time.Now())
+	e.stopping(time.Now(), errors.New("stopping"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		e.Run(ctx)
+	}()
+	cancel()
+
+	select {
+	case <-runDone:
+	case <-time.After(time.Second):
+		t.Fatalf("Run() did not return after the context was done")
+	}
+
+	for _, want := range []Status{Success, Failure} {
+		select {
+		case got := <-sCh:
+			if got != want {
+				t.Errorf("status = %s, want %s", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("status %s not delivered", want)
+		}
+	}
+
+}
+
+func Test_eventProbe_Notify_afterRun(t *testing.T) {
+	e := newEventProbe(Failure)
+
+	sCh := make(chan Status)
+	e.Notify(sCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e.Run(ctx)
+
+	// the queues are closed, changes must neither panic nor be delivered
+	e.ready(time.Now())
+
+	select {
+	case got := <-sCh:
+		t.Errorf("unexpected status %s after Run returned", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
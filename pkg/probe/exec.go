@@ -0,0 +1,56 @@
+package probe
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/shell"
+	"github.com/pkg/errors"
+)
+
+func NewExecForConfig(cfg options.ProbeConfig, initialStatus Status) (*execProbe, error) {
+	if len(strings.TrimSpace(cfg.Command)) == 0 {
+		return nil, errors.New("missing command for exec probe")
+	}
+
+	p, err := NewExec(
+		cfg.Command,
+		cfg.RequestTimeout,
+		cfg.InitialDelay,
+		cfg.Period,
+		cfg.SuccessThreshold,
+		cfg.FailureThreshold,
+		initialStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.jitter = cfg.Jitter
+
+	return p, nil
+}
+
+func NewExec(command string, timeout, initialDelay, period time.Duration, successThreshold, failureThreshold int, initialStatus Status) (*execProbe, error) {
+	e := &execProbe{
+		command: command,
+	}
+	e.runner = newRunner(e.check, initialDelay, period, timeout, successThreshold, failureThreshold, initialStatus)
+
+	return e, nil
+}
+
+var _ Interface = &execProbe{}
+
+// execProbe runs a shell command and succeeds if it exits with code 0, like the kubernetes exec probe.
+// The exit code is reported as status code. On timeout, the command is killed along with its descendants.
+type execProbe struct {
+	*runner
+	command string
+}
+
+func (e *execProbe) check(ctx context.Context) (int, error) {
+	return shell.Run(ctx, e.command)
+}
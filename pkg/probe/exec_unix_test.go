@@ -0,0 +1,47 @@
+//go:build !windows
+// +build !windows
+
+package probe
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_execProbe_check(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		timeout  time.Duration
+		wantCode int
+		wantErr  bool
+	}{
+		{name: "ok", command: "true", timeout: time.Second, wantCode: 0, wantErr: false},
+		{name: "err_exit_code", command: "echo not ready; exit 3", timeout: time.Second, wantCode: 3, wantErr: true},
+		{name: "err_timeout", command: "sleep 5; true", timeout: 100 * time.Millisecond, wantCode: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewExec(tt.command, tt.timeout, 0, time.Second, 1, 1, Failure)
+			if err != nil {
+				t.Fatalf("NewExec() error = %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			start := time.Now()
+			code, err := e.check(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("execProbe.check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if code != tt.wantCode {
+				t.Errorf("execProbe.check() code = %d, want %d", code, tt.wantCode)
+			}
+			if elapsed := time.Since(start); elapsed > tt.timeout+time.Second {
+				t.Errorf("execProbe.check() returned after %s, timeout was %s", elapsed, tt.timeout)
+			}
+		})
+	}
+}
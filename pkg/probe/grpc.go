@@ -0,0 +1,89 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/version"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func NewGRPCForConfig(cfg options.ProbeConfig, initialStatus Status) (*grpcProbe, error) {
+	if len(cfg.Target.Val.Host) == 0 {
+		return nil, errors.Errorf("missing host in grpc target %s", cfg.Target.String())
+	}
+
+	p, err := NewGRPC(
+		cfg.Target.Val.Host,
+		cfg.Service,
+		cfg.RequestTimeout,
+		cfg.InitialDelay,
+		cfg.Period,
+		cfg.SuccessThreshold,
+		cfg.FailureThreshold,
+		initialStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.jitter = cfg.Jitter
+
+	return p, nil
+}
+
+func NewGRPC(address, service string, timeout, initialDelay, period time.Duration, successThreshold, failureThreshold int, initialStatus Status) (*grpcProbe, error) {
+	g := &grpcProbe{
+		address: address,
+		service: service,
+	}
+	g.runner = newRunner(g.check, initialDelay, period, timeout, successThreshold, failureThreshold, initialStatus)
+
+	return g, nil
+}
+
+var _ Interface = &grpcProbe{}
+
+// grpcProbe queries the grpc.health.v1.Health service, like the kubernetes grpc probe.
+// A new connection is established for every check. The serving status is reported as status code.
+// Without a timeout, a check is bounded by the period, as the blocking dial retries until its context ends.
+type grpcProbe struct {
+	*runner
+	address string
+	service string
+}
+
+func (g *grpcProbe) check(ctx context.Context) (int, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.period)
+		defer cancel()
+	}
+
+	conn, err := grpc.DialContext(
+		ctx,
+		g.address,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithUserAgent(fmt.Sprintf("%s/%s grpc-probe", options.ProjectName, version.GetInfo())),
+	)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to connect to %s", g.address)
+	}
+	defer conn.Close()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: g.service})
+	if err != nil {
+		return 0, errors.Wrapf(err, "health check of service %q failed", g.service)
+	}
+
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return int(res.GetStatus()), errors.Errorf("service %q is %s", g.service, res.GetStatus())
+	}
+
+	return int(res.GetStatus()), nil
+}
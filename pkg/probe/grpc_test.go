@@ -0,0 +1,94 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func Test_grpcProbe_check(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("serving", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("not-serving", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	tests := []struct {
+		name    string
+		service string
+		wantErr bool
+	}{
+		{
+			name:    "ok_overall",
+			service: "",
+			wantErr: false,
+		},
+		{
+			name:    "ok_serving",
+			service: "serving",
+			wantErr: false,
+		},
+		{
+			name:    "err_not_serving",
+			service: "not-serving",
+			wantErr: true,
+		},
+		{
+			name:    "err_unknown_service",
+			service: "unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewGRPC(listener.Addr().String(), tt.service, time.Second, 0, time.Second, 1, 1, Failure)
+			if err != nil {
+				t.Fatalf("NewGRPC() error = %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			if _, err := p.check(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("grpcProbe.check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_grpcProbe_check_withoutTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	period := 200 * time.Millisecond
+	p, err := NewGRPC(address, "", 0, 0, period, 1, 1, Success)
+	if err != nil {
+		t.Fatalf("NewGRPC() error = %v", err)
+	}
+
+	start := time.Now()
+	if _, err := p.check(context.Background()); err == nil {
+		t.Errorf("grpcProbe.check() succeeded on a closed listener")
+	}
+
+	if elapsed := time.Since(start); elapsed > period+time.Second {
+		t.Errorf("grpcProbe.check() took %s, want it bounded by the period of %s", elapsed, period)
+	}
+}
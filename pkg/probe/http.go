@@ -2,13 +2,11 @@ package probe
 
 import (
 	"context"
+	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"time"
 
-	"sync"
-
 	"fmt"
 
 	"net/url"
@@ -29,175 +27,173 @@ func NewHTTPForConfig(cfg options.ProbeConfig, initialStatus Status) (*httpProbe
 		},
 	}
 
+	if cfg.KubeletCompatible {
+		client = &http.Client{
+			Timeout:       cfg.RequestTimeout,
+			Transport:     transport.NewKubelet(),
+			CheckRedirect: kubeletCheckRedirect,
+		}
+	}
+
 	u, err := url.Parse(cfg.Target.String())
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to parse url %s", cfg.Target.String())
 	}
 
-	return NewHTTP(
+	header := make(http.Header)
+	for key, value := range cfg.Headers {
+		header.Set(key, value)
+	}
+	if len(cfg.Host) != 0 {
+		header.Set("Host", cfg.Host)
+	}
+
+	matchers := make([]BodyMatcher, 0)
+	if len(cfg.BodyRegex) != 0 {
+		m, err := NewRegexBodyMatcher(cfg.BodyRegex)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	if len(cfg.BodyJSONPath) != 0 {
+		m, err := NewJSONPathBodyMatcher(cfg.BodyJSONPath, cfg.BodyJSONValue)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+
+	h, err := NewHTTP(
 		client,
+		cfg.Method,
 		u,
+		header,
+		cfg.ExpectedStatusCodes,
 		cfg.InitialDelay,
 		cfg.Period,
 		cfg.SuccessThreshold,
 		cfg.FailureThreshold,
 		initialStatus,
+		matchers...,
 	)
-}
+	if err != nil {
+		return nil, err
+	}
 
-func NewHTTP(client *http.Client, target *url.URL, initialDelay, period time.Duration, successThreshold, failureThreshold int, initialStatus Status) (*httpProbe, error) {
-
-	return &httpProbe{
-		initialDelay:     initialDelay,
-		period:           period,
-		successThreshold: successThreshold,
-		failureThreshold: failureThreshold,
-		target:           target,
-		client:           client,
-		status:           initialStatus,
-		bucket:           make([]Status, 10),
-		subscribers:      make([]chan Status, 0),
-	}, nil
-}
+	h.jitter = cfg.Jitter
 
-var _ Interface = &httpProbe{}
+	if cfg.KubeletCompatible {
+		h.maxBodyLength = kubeletMaxBodyLength
+	}
 
-type httpProbe struct {
-	client           *http.Client
-	initialDelay     time.Duration
-	period           time.Duration
-	Timeout          time.Duration
-	successThreshold int
-	failureThreshold int
-	target           *url.URL
-	status           Status
-	bucket           []Status
-	bucketMu         sync.Mutex
-	subscribers      []chan Status
+	return h, nil
 }
 
-func (h *httpProbe) Check() error {
-	if h.Timeout > h.period {
-		return errors.Errorf("probe timeout of %s must be lower than period %s", h.Timeout.String(), h.period.String())
+// kubeletMaxBodyLength is the number of response body bytes read by kubelet http probes.
+const kubeletMaxBodyLength = 10 * 1024
+
+// kubeletCheckRedirect follows redirects to the same host only. A redirect to another host
+// stops and returns the redirect response, which counts as success like in kubelet.
+func kubeletCheckRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Hostname() != via[0].URL.Hostname() {
+		return http.ErrUseLastResponse
+	}
+
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
 	}
 
 	return nil
 }
 
-func (h *httpProbe) Run(ctx context.Context) {
-	_ = <-time.After(h.initialDelay)
-
-loop:
-	for {
-		select {
-		case <-time.After(h.period):
-			go h.check()
-		case <-ctx.Done():
-			log.Printf("probe closed by context with: %s", ctx.Err())
-			break loop
-		}
+// NewHTTP creates a probe performing requests with the given method, target and header. The response
+// status code must be one of expectedStatusCodes, any 2xx and 3xx code is accepted if none are given.
+// The response body must satisfy all bodyMatchers.
+func NewHTTP(client *http.Client, method string, target *url.URL, header http.Header, expectedStatusCodes []int, initialDelay, period time.Duration, successThreshold, failureThreshold int, initialStatus Status, bodyMatchers ...BodyMatcher) (*httpProbe, error) {
+	if len(method) == 0 {
+		method = http.MethodGet
 	}
-}
 
-func (h *httpProbe) Notify(sCh chan Status) {
-	h.subscribers = append(h.subscribers, sCh)
+	h := &httpProbe{
+		method:              method,
+		target:              target,
+		header:              header,
+		expectedStatusCodes: expectedStatusCodes,
+		bodyMatchers:        bodyMatchers,
+		client:              client,
+	}
+	h.runner = newRunner(h.check, initialDelay, period, client.Timeout, successThreshold, failureThreshold, initialStatus)
+
+	return h, nil
 }
 
-func (h *httpProbe) check() {
-	req, err := http.NewRequest("GET", h.target.String(), nil)
-	if err != nil {
-		h.pushStatus(Failure, errors.Wrap(err, "failed to build request"))
-		return
-	}
+var _ Interface = &httpProbe{}
 
-	req.RemoteAddr = h.target.Host
+type httpProbe struct {
+	*runner
+	client              *http.Client
+	method              string
+	target              *url.URL
+	header              http.Header
+	expectedStatusCodes []int
+	bodyMatchers        []BodyMatcher
+	maxBodyLength       int64
+}
 
-	res, err := h.client.Do(req)
+func (h *httpProbe) check(ctx context.Context) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, h.method, h.target.String(), nil)
 	if err != nil {
-		h.pushStatus(Failure, err)
-		return
+		return 0, errors.Wrap(err, "failed to build request")
 	}
 
-	if _, err := ioutil.ReadAll(res.Body); err != nil {
-		h.pushStatus(Failure, errors.Wrap(err, "failed to read response"))
-		return
+	req.RemoteAddr = h.target.Host
+	for key, values := range h.header {
+		req.Header[key] = values
 	}
-
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		h.pushStatus(Failure, errors.Errorf("bad response code: %d", res.StatusCode))
-		return
+	if host := h.header.Get("Host"); len(host) != 0 {
+		req.Host = host
 	}
 
-	h.pushStatus(Success, nil)
-}
-
-func (h *httpProbe) pushStatus(status Status, err error) {
-	h.bucketMu.Lock()
-	defer h.bucketMu.Unlock()
-
-	bucket := make([]Status, 0)
-	bucket = append(bucket, status)
-	bucket = append(bucket, h.bucket[0:9]...)
-	h.bucket = bucket
-
-	//log.Printf("Bucket: %+v", h.bucket)
-
+	res, err := h.client.Do(req)
 	if err != nil {
-		log.Println(err.Error())
+		return 0, err
 	}
+	defer res.Body.Close()
 
-	h.evalStatus()
-}
-
-func (h *httpProbe) evalStatus() {
-	if len(h.bucket[0]) == 0 {
-		return
+	var reader io.Reader = res.Body
+	if h.maxBodyLength > 0 {
+		reader = io.LimitReader(res.Body, h.maxBodyLength)
 	}
 
-	nextStatus := h.bucket[0]
-
-	if nextStatus == h.status {
-		return
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return res.StatusCode, errors.Wrap(err, "failed to read response")
 	}
 
-	var threshold int
-	switch nextStatus {
-	case Success:
-		threshold = h.successThreshold
-	case Failure:
-		threshold = h.failureThreshold
-	default:
-		log.Printf("unknown next status: %s", nextStatus)
-		return
+	if !h.isExpectedStatusCode(res.StatusCode) {
+		return res.StatusCode, errors.Errorf("bad response code: %d", res.StatusCode)
 	}
 
-	var consecutive int
-	for _, status := range h.bucket {
-		if status == nextStatus {
-			consecutive++
-		} else {
-			break
+	for _, match := range h.bodyMatchers {
+		if err := match(body); err != nil {
+			return res.StatusCode, errors.Wrap(err, "bad response body")
 		}
 	}
 
-	if consecutive >= threshold {
-		h.setStatus(nextStatus)
-	}
+	return res.StatusCode, nil
 }
 
-func (h *httpProbe) setStatus(status Status) {
-	if h.status == status {
-		return
+func (h *httpProbe) isExpectedStatusCode(code int) bool {
+	if len(h.expectedStatusCodes) == 0 {
+		return code >= http.StatusOK && code < http.StatusBadRequest
 	}
 
-	h.status = status
-	h.notifySubscribers(status)
-}
-
-func (h *httpProbe) notifySubscribers(status Status) {
-	for _, sCh := range h.subscribers {
-		go func(ch chan Status) {
-			ch <- status
-		}(sCh)
+	for _, expected := range h.expectedStatusCodes {
+		if code == expected {
+			return true
+		}
 	}
+
+	return false
 }
@@ -0,0 +1,112 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_httpProbe_check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Host != "health.local":
+			w.WriteHeader(http.StatusMisdirectedRequest)
+		case r.Header.Get("Authorization") != "Bearer token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method != http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	target, _ := url.Parse(server.URL)
+
+	type args struct {
+		method              string
+		header              http.Header
+		expectedStatusCodes []int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "ok_default_status_range",
+			args: args{
+				method: http.MethodHead,
+				header: http.Header{"Host": {"health.local"}, "Authorization": {"Bearer token"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "ok_expected_status",
+			args: args{
+				method:              http.MethodGet,
+				header:              http.Header{"Host": {"health.local"}, "Authorization": {"Bearer token"}},
+				expectedStatusCodes: []int{http.StatusMethodNotAllowed},
+			},
+			wantErr: false,
+		},
+		{
+			name: "err_unexpected_status",
+			args: args{
+				method:              http.MethodHead,
+				header:              http.Header{"Host": {"health.local"}, "Authorization": {"Bearer token"}},
+				expectedStatusCodes: []int{http.StatusOK},
+			},
+			wantErr: true,
+		},
+		{
+			name: "err_missing_host",
+			args: args{
+				method: http.MethodHead,
+				header: http.Header{"Authorization": {"Bearer token"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewHTTP(server.Client(), tt.args.method, target, tt.args.header, tt.args.expectedStatusCodes, 0, time.Second, 1, 1, Failure)
+			if err != nil {
+				t.Fatalf("NewHTTP() error = %v", err)
+			}
+			if _, err := p.check(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("httpProbe.check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_kubeletCheckRedirect(t *testing.T) {
+	origin, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/health", nil)
+
+	tests := []struct {
+		name    string
+		target  string
+		via     int
+		wantErr error
+	}{
+		{name: "ok_same_host", target: "http://localhost:8080/healthz", via: 1, wantErr: nil},
+		{name: "ok_same_host_other_port", target: "https://localhost:8443/healthz", via: 1, wantErr: nil},
+		{name: "stop_other_host", target: "http://example.com/health", via: 1, wantErr: http.ErrUseLastResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			via := make([]*http.Request, tt.via)
+			for n := range via {
+				via[n] = origin
+			}
+			if err := kubeletCheckRedirect(req, via); err != tt.wantErr {
+				t.Errorf("kubeletCheckRedirect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
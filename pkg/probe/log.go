@@ -0,0 +1,66 @@
+package probe
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/pkg/errors"
+)
+
+// LineObserver is implemented by probes deriving their status from the output of the process.
+type LineObserver interface {
+	ObserveLine(t time.Time, line string)
+}
+
+func NewLogForConfig(cfg options.ProbeConfig, initialStatus Status) (*logProbe, error) {
+	if len(cfg.LogReadyRegex) == 0 {
+		return nil, errors.New("missing ready regex for log probe")
+	}
+
+	return NewLog(cfg.LogReadyRegex, cfg.LogShutdownRegex, initialStatus)
+}
+
+// NewLog creates a probe which succeeds once a line matches readyExpr and fails for good once a line
+// matches shutdownExpr. The shutdown expression is optional.
+func NewLog(readyExpr, shutdownExpr string, initialStatus Status) (*logProbe, error) {
+	l := &logProbe{
+		eventProbe: newEventProbe(initialStatus),
+	}
+
+	var err error
+	if l.readyRe, err = regexp.Compile(readyExpr); err != nil {
+		return nil, errors.Wrapf(err, "invalid ready regex %q", readyExpr)
+	}
+
+	if len(shutdownExpr) != 0 {
+		if l.shutdownRe, err = regexp.Compile(shutdownExpr); err != nil {
+			return nil, errors.Wrapf(err, "invalid shutdown regex %q", shutdownExpr)
+		}
+	}
+
+	return l, nil
+}
+
+var _ Interface = &logProbe{}
+var _ LineObserver = &logProbe{}
+
+// logProbe watches the output of the process for services without a health endpoint.
+// Every line written by the process counts as check.
+type logProbe struct {
+	*eventProbe
+	readyRe    *regexp.Regexp
+	shutdownRe *regexp.Regexp
+}
+
+// ObserveLine evaluates a line written by the process at t.
+func (l *logProbe) ObserveLine(t time.Time, line string) {
+	l.observed()
+
+	switch {
+	case l.shutdownRe != nil && l.shutdownRe.MatchString(line):
+		l.stopping(t, errors.Errorf("shutdown line %q", line))
+	case l.readyRe.MatchString(line):
+		l.ready(t)
+	}
+}
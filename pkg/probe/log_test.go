@@ -0,0 +1,68 @@
+package probe
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_logProbe_ObserveLine(t *testing.T) {
+	tests := []struct {
+		name         string
+		shutdownExpr string
+		lines        []string
+		want         []Status
+	}{
+		{
+			name:  "ok_ready",
+			lines: []string{"Starting", "Listening on :8080"},
+			want:  []Status{Success},
+		},
+		{
+			name:         "ok_ready_and_shutdown",
+			shutdownExpr: "^Closing",
+			lines:        []string{"Listening on :8080", "GET / 200", "Closing http server", "Listening on :8080"},
+			want:         []Status{Success, Failure},
+		},
+		{
+			name:  "ok_not_ready",
+			lines: []string{"Starting", "Connecting to database"},
+			want:  []Status{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewLog("Listening on", tt.shutdownExpr, Failure)
+			if err != nil {
+				t.Fatalf("NewLog() error = %v", err)
+			}
+
+			sCh := make(chan Status)
+			l.Notify(sCh)
+
+			for _, line := range tt.lines {
+				l.ObserveLine(time.Now(), line)
+			}
+
+			for n, want := range tt.want {
+				select {
+				case got := <-sCh:
+					if got != want {
+						t.Errorf("status change %d = %s, want %s", n, got, want)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("status change %d to %s not notified", n, want)
+				}
+			}
+
+			select {
+			case got := <-sCh:
+				t.Errorf("unexpected status change to %s", got)
+			case <-time.After(10 * time.Millisecond):
+			}
+
+			if got := l.Stats().Checks; got != len(tt.lines) {
+				t.Errorf("Stats().Checks = %d, want %d", got, len(tt.lines))
+			}
+		})
+	}
+}
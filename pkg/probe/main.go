@@ -1,9 +1,76 @@
 package probe
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/pkg/errors"
+)
 
 type Interface interface {
 	Run(ctx context.Context)
 	Check() error
 	Notify(sCh chan Status)
+	Stats() Stats
+	History() []Result
+}
+
+// Result of a single check.
+type Result struct {
+	Time       time.Time
+	Latency    time.Duration
+	Status     Status
+	StatusCode int
+	Err        error
+}
+
+// Stats counts the checks performed by a probe and the ticks skipped because a check was still in flight.
+type Stats struct {
+	Checks  int
+	Skipped int
+}
+
+// NewForConfig creates the probe implementation selected by the type of the config.
+func NewForConfig(cfg options.ProbeConfig, initialStatus Status) (Interface, error) {
+	switch cfg.Type {
+	case options.ProbeTypeHTTP:
+		p, err := NewHTTPForConfig(cfg, initialStatus)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case options.ProbeTypeTCP:
+		p, err := NewTCPForConfig(cfg, initialStatus)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case options.ProbeTypeExec:
+		p, err := NewExecForConfig(cfg, initialStatus)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case options.ProbeTypeGRPC:
+		p, err := NewGRPCForConfig(cfg, initialStatus)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case options.ProbeTypeLog:
+		p, err := NewLogForConfig(cfg, initialStatus)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case options.ProbeTypeNotify:
+		p, err := NewNotifyForConfig(cfg, initialStatus)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	default:
+		return nil, errors.Errorf("unsupported probe type %q", cfg.Type)
+	}
 }
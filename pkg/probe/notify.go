@@ -0,0 +1,45 @@
+package probe
+
+import (
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/pkg/errors"
+)
+
+// NotificationObserver is implemented by probes deriving their status from sd_notify notifications of the process.
+type NotificationObserver interface {
+	ObserveNotification(t time.Time, key, value string)
+}
+
+func NewNotifyForConfig(cfg options.ProbeConfig, initialStatus Status) (*notifyProbe, error) {
+	return NewNotify(initialStatus)
+}
+
+// NewNotify creates a probe which succeeds once the process sent READY=1 and fails for good once it sent STOPPING=1.
+func NewNotify(initialStatus Status) (*notifyProbe, error) {
+	return &notifyProbe{
+		eventProbe: newEventProbe(initialStatus),
+	}, nil
+}
+
+var _ Interface = &notifyProbe{}
+var _ NotificationObserver = &notifyProbe{}
+
+// notifyProbe follows the state the process reports via sd_notify, like systemd does for Type=notify services.
+// Every notification counts as check.
+type notifyProbe struct {
+	*eventProbe
+}
+
+// ObserveNotification evaluates a single assignment, e.g. READY=1, sent by the process at t.
+func (n *notifyProbe) ObserveNotification(t time.Time, key, value string) {
+	n.observed()
+
+	switch {
+	case key == "STOPPING" && value == "1":
+		n.stopping(t, errors.New("process notified STOPPING=1"))
+	case key == "READY" && value == "1":
+		n.ready(t)
+	}
+}
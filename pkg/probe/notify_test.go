@@ -0,0 +1,70 @@
+package probe
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_notifyProbe_ObserveNotification(t *testing.T) {
+	type notification struct {
+		key   string
+		value string
+	}
+	tests := []struct {
+		name          string
+		notifications []notification
+		want          []Status
+	}{
+		{
+			name:          "ok_ready",
+			notifications: []notification{{"STATUS", "Starting"}, {"READY", "1"}},
+			want:          []Status{Success},
+		},
+		{
+			name:          "ok_ready_and_stopping",
+			notifications: []notification{{"READY", "1"}, {"STOPPING", "1"}, {"READY", "1"}},
+			want:          []Status{Success, Failure},
+		},
+		{
+			name:          "ok_status_only",
+			notifications: []notification{{"STATUS", "Connecting to database"}, {"MAINPID", "42"}},
+			want:          []Status{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNotify(Failure)
+			if err != nil {
+				t.Fatalf("NewNotify() error = %v", err)
+			}
+
+			sCh := make(chan Status)
+			n.Notify(sCh)
+
+			for _, notification := range tt.notifications {
+				n.ObserveNotification(time.Now(), notification.key, notification.value)
+			}
+
+			for i, want := range tt.want {
+				select {
+				case got := <-sCh:
+					if got != want {
+						t.Errorf("status change %d = %s, want %s", i, got, want)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("status change %d to %s not notified", i, want)
+				}
+			}
+
+			select {
+			case got := <-sCh:
+				t.Errorf("unexpected status change to %s", got)
+			case <-time.After(10 * time.Millisecond):
+			}
+
+			if got := n.Stats().Checks; got != len(tt.notifications) {
+				t.Errorf("Stats().Checks = %d, want %d", got, len(tt.notifications))
+			}
+		})
+	}
+}
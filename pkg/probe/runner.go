@@ -0,0 +1,258 @@
+package probe
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultHistoryLimit is the number of results kept per probe. Older results are dropped, as the results
+// around the shutdown are the ones of interest.
+const defaultHistoryLimit = 1000
+
+// checkFunc performs a single probe attempt and returns the status code of the response, if any.
+// A nil error counts as success.
+type checkFunc func(ctx context.Context) (int, error)
+
+func newRunner(check checkFunc, initialDelay, period, timeout time.Duration, successThreshold, failureThreshold int, initialStatus Status) *runner {
+	return &runner{
+		check:            check,
+		initialDelay:     initialDelay,
+		period:           period,
+		timeout:          timeout,
+		successThreshold: successThreshold,
+		failureThreshold: failureThreshold,
+		status:           initialStatus,
+		subscribers:      make([]chan Status, 0),
+		history:          make([]Result, 0),
+		historyLimit:     defaultHistoryLimit,
+	}
+}
+
+// runner holds the scheduling and threshold evaluation shared by all probe implementations.
+// Like kubelet, it performs at most one check at a time; ticks while a check is in flight are skipped.
+type runner struct {
+	check            checkFunc
+	initialDelay     time.Duration
+	period           time.Duration
+	timeout          time.Duration
+	jitter           float64
+	successThreshold int
+	failureThreshold int
+	status           Status
+	// successes and failures count the consecutive results of the same status, at most one of them is non-zero.
+	successes    int
+	failures     int
+	statusMu     sync.Mutex
+	subscribers  []chan Status
+	stats        Stats
+	history      []Result
+	historyLimit int
+	statsMu      sync.Mutex
+}
+
+func (r *runner) Check() error {
+	if r.period <= 0 {
+		return errors.Errorf("probe period of %s must be positive", r.period.String())
+	}
+
+	// consecutive results are counted without limit, so any positive threshold can be reached
+	if r.successThreshold < 1 || r.failureThreshold < 1 {
+		return errors.Errorf("probe thresholds must be positive, got success %d and failure %d", r.successThreshold, r.failureThreshold)
+	}
+
+	if r.timeout >= r.period {
+		return errors.Errorf("probe timeout of %s must be lower than period %s", r.timeout.String(), r.period.String())
+	}
+
+	if r.jitter < 0 || r.jitter >= 1 {
+		return errors.Errorf("probe jitter of %.2f must be within [0, 1)", r.jitter)
+	}
+
+	return nil
+}
+
+func (r *runner) Run(ctx context.Context) {
+	select {
+	case <-time.After(r.initialDelay):
+	case <-ctx.Done():
+		log.Printf("probe closed by context with: %s", ctx.Err())
+		return
+	}
+
+	// like kubelet, only the first tick is delayed, so the jitter is not counted as a check in flight
+	r.delayByJitter(ctx)
+	if ctx.Err() != nil {
+		log.Printf("probe closed by context with: %s", ctx.Err())
+		return
+	}
+
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+
+	// a single worker performs the checks, so results are applied in the order of the ticks
+	go func() {
+		defer close(done)
+
+		for range ticks {
+			if ctx.Err() != nil {
+				continue
+			}
+			r.probe(ctx)
+		}
+	}()
+
+	ticker := time.NewTicker(r.period)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case t := <-ticker.C:
+			select {
+			case ticks <- t:
+			default:
+				r.statsMu.Lock()
+				r.stats.Skipped++
+				r.statsMu.Unlock()
+				log.Printf("probe check still in flight, skipped tick at %s", t.Format(time.RFC3339Nano))
+			}
+		case <-ctx.Done():
+			log.Printf("probe closed by context with: %s", ctx.Err())
+			break loop
+		}
+	}
+
+	close(ticks)
+	<-done
+}
+
+func (r *runner) Stats() Stats {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	return r.stats
+}
+
+// History returns the results of the checks performed so far, in order. Only the most recent results are kept.
+func (r *runner) History() []Result {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+
+	history := make([]Result, len(r.history))
+	copy(history, r.history)
+
+	return history
+}
+
+func (r *runner) Notify(sCh chan Status) {
+	r.subscribers = append(r.subscribers, sCh)
+}
+
+func (r *runner) delayByJitter(ctx context.Context) {
+	if r.jitter <= 0 {
+		return
+	}
+
+	select {
+	case <-time.After(time.Duration(rand.Float64() * r.jitter * float64(r.period))):
+	case <-ctx.Done():
+	}
+}
+
+func (r *runner) probe(ctx context.Context) {
+	r.statsMu.Lock()
+	r.stats.Checks++
+	r.statsMu.Unlock()
+
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	code, err := r.check(ctx)
+
+	result := Result{
+		Time:       start,
+		Latency:    time.Since(start),
+		Status:     Success,
+		StatusCode: code,
+		Err:        err,
+	}
+	if err != nil {
+		result.Status = Failure
+	}
+
+	r.statsMu.Lock()
+	r.history = append(r.history, result)
+	if len(r.history) > r.historyLimit {
+		r.history = r.history[len(r.history)-r.historyLimit:]
+	}
+	r.statsMu.Unlock()
+
+	r.pushStatus(result.Status, err)
+}
+
+func (r *runner) pushStatus(status Status, err error) {
+	r.statusMu.Lock()
+	defer r.statusMu.Unlock()
+
+	if status == Success {
+		r.successes++
+		r.failures = 0
+	} else {
+		r.failures++
+		r.successes = 0
+	}
+
+	if err != nil {
+		log.Println(err.Error())
+	}
+
+	r.evalStatus(status)
+}
+
+// evalStatus changes the status once the consecutive results of nextStatus reached its threshold.
+func (r *runner) evalStatus(nextStatus Status) {
+	if nextStatus == r.status {
+		return
+	}
+
+	var consecutive, threshold int
+	switch nextStatus {
+	case Success:
+		consecutive, threshold = r.successes, r.successThreshold
+	case Failure:
+		consecutive, threshold = r.failures, r.failureThreshold
+	default:
+		log.Printf("unknown next status: %s", nextStatus)
+		return
+	}
+
+	if consecutive >= threshold {
+		r.setStatus(nextStatus)
+	}
+}
+
+func (r *runner) setStatus(status Status) {
+	if r.status == status {
+		return
+	}
+
+	r.status = status
+	r.notifySubscribers(status)
+}
+
+func (r *runner) notifySubscribers(status Status) {
+	for _, sCh := range r.subscribers {
+		go func(ch chan Status) {
+			ch <- status
+		}(sCh)
+	}
+}
@@ -0,0 +1,226 @@
+package probe
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_runner_Run_nonOverlapping(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+
+	check := func(ctx context.Context) (int, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		return 0, nil
+	}
+
+	r := newRunner(check, 0, 20*time.Millisecond, 0, 1, 1, Failure)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	r.Run(ctx)
+
+	if maxInFlight != 1 {
+		t.Errorf("runner.Run() max checks in flight = %d, want 1", maxInFlight)
+	}
+
+	stats := r.Stats()
+	if stats.Checks == 0 {
+		t.Errorf("runner.Run() checks = %d, want > 0", stats.Checks)
+	}
+	if stats.Skipped == 0 {
+		t.Errorf("runner.Run() skipped = %d, want > 0", stats.Skipped)
+	}
+}
+
+func Test_runner_Run_jitter(t *testing.T) {
+	period := 100 * time.Millisecond
+
+	// checks take most of the period, a jitter delaying each of them would overlap the next tick
+	check := func(ctx context.Context) (int, error) {
+		time.Sleep(55 * time.Millisecond)
+		return 0, nil
+	}
+
+	r := newRunner(check, 0, period, 0, 1, 1, Failure)
+	r.jitter = 0.5
+
+	ctx, cancel := context.WithTimeout(context.Background(), 7*period)
+	defer cancel()
+
+	r.Run(ctx)
+
+	stats := r.Stats()
+	if stats.Checks == 0 {
+		t.Errorf("runner.Run() checks = %d, want > 0", stats.Checks)
+	}
+	if stats.Skipped != 0 {
+		t.Errorf("runner.Run() skipped = %d, want 0", stats.Skipped)
+	}
+}
+
+func Test_runner_Check(t *testing.T) {
+	check := func(ctx context.Context) (int, error) {
+		return 0, nil
+	}
+
+	tests := []struct {
+		name             string
+		period           time.Duration
+		timeout          time.Duration
+		jitter           float64
+		successThreshold int
+		failureThreshold int
+		wantErr          bool
+	}{
+		{name: "ok", period: time.Second, timeout: 500 * time.Millisecond, successThreshold: 1, failureThreshold: 3},
+		{name: "ok_without_timeout", period: time.Second, successThreshold: 1, failureThreshold: 3},
+		{name: "ok_startup_failure_threshold", period: 2 * time.Second, timeout: time.Second, successThreshold: 1, failureThreshold: 30},
+		{name: "err_timeout_above_period", period: time.Second, timeout: 2 * time.Second, successThreshold: 1, failureThreshold: 3, wantErr: true},
+		{name: "err_timeout_equal_period", period: time.Second, timeout: time.Second, successThreshold: 1, failureThreshold: 3, wantErr: true},
+		{name: "err_zero_period", period: 0, successThreshold: 1, failureThreshold: 3, wantErr: true},
+		{name: "err_zero_success_threshold", period: time.Second, successThreshold: 0, failureThreshold: 3, wantErr: true},
+		{name: "err_negative_failure_threshold", period: time.Second, successThreshold: 1, failureThreshold: -1, wantErr: true},
+		{name: "err_jitter", period: time.Second, successThreshold: 1, failureThreshold: 3, jitter: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRunner(check, 0, tt.period, tt.timeout, tt.successThreshold, tt.failureThreshold, Failure)
+			r.jitter = tt.jitter
+
+			if err := r.Check(); (err != nil) != tt.wantErr {
+				t.Errorf("runner.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_runner_pushStatus(t *testing.T) {
+	tests := []struct {
+		name             string
+		successThreshold int
+		failureThreshold int
+		results          []Status
+		want             []Status
+	}{
+		{
+			name:             "startup_failure_threshold_above_ten",
+			successThreshold: 1,
+			failureThreshold: 30,
+			results:          repeatStatus(Failure, 30),
+			want:             []Status{Failure},
+		},
+		{
+			name:             "below_failure_threshold",
+			successThreshold: 1,
+			failureThreshold: 30,
+			results:          repeatStatus(Failure, 29),
+			want:             nil,
+		},
+		{
+			name:             "success_resets_failures",
+			successThreshold: 2,
+			failureThreshold: 3,
+			results:          []Status{Failure, Failure, Success, Failure, Failure, Success, Success, Failure, Failure, Failure},
+			want:             []Status{Success, Failure},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRunner(nil, 0, time.Second, 0, tt.successThreshold, tt.failureThreshold, Unknown)
+			sCh := make(chan Status, len(tt.results))
+			r.Notify(sCh)
+
+			for _, status := range tt.results {
+				r.pushStatus(status, nil)
+				// transitions are delivered asynchronously, wait for them to keep the order
+				time.Sleep(time.Millisecond)
+			}
+
+			var got []Status
+		loop:
+			for {
+				select {
+				case status := <-sCh:
+					got = append(got, status)
+				case <-time.After(50 * time.Millisecond):
+					break loop
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runner transitions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func repeatStatus(status Status, n int) []Status {
+	statuses := make([]Status, n)
+	for i := range statuses {
+		statuses[i] = status
+	}
+
+	return statuses
+}
+
+func Test_runner_History(t *testing.T) {
+	var n int
+	check := func(ctx context.Context) (int, error) {
+		n++
+		if n%2 == 0 {
+			return n, errors.New("not ready")
+		}
+		return n, nil
+	}
+
+	r := newRunner(check, 0, time.Second, 0, 1, 1, Unknown)
+	r.historyLimit = 3
+
+	for i := 0; i < 5; i++ {
+		r.probe(context.Background())
+	}
+
+	history := r.History()
+	if len(history) != 3 {
+		t.Fatalf("runner.History() len = %d, want 3", len(history))
+	}
+
+	for i, want := range []struct {
+		code   int
+		status Status
+	}{
+		{code: 3, status: Success},
+		{code: 4, status: Failure},
+		{code: 5, status: Success},
+	} {
+		if history[i].StatusCode != want.code || history[i].Status != want.status {
+			t.Errorf("runner.History()[%d] = %d %s, want %d %s", i, history[i].StatusCode, history[i].Status, want.code, want.status)
+		}
+		if i > 0 && history[i].Time.Before(history[i-1].Time) {
+			t.Errorf("runner.History()[%d] recorded before its predecessor", i)
+		}
+	}
+
+	if stats := r.Stats(); stats.Checks != 5 {
+		t.Errorf("runner.Stats() checks = %d, want 5", stats.Checks)
+	}
+}
@@ -3,6 +3,8 @@ package probe
 type Status string
 
 const (
+	// Unknown is the initial status of probes without a presumed outcome, e.g. the startup probe.
+	Unknown Status = "unknown"
 	Success Status = "success"
 	Failure        = "failure"
 )
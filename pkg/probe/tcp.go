@@ -0,0 +1,61 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
+	"github.com/pkg/errors"
+)
+
+func NewTCPForConfig(cfg options.ProbeConfig, initialStatus Status) (*tcpProbe, error) {
+	if len(cfg.Target.Val.Host) == 0 {
+		return nil, errors.Errorf("missing host in tcp target %s", cfg.Target.String())
+	}
+
+	p, err := NewTCP(
+		cfg.Target.Val.Host,
+		cfg.RequestTimeout,
+		cfg.InitialDelay,
+		cfg.Period,
+		cfg.SuccessThreshold,
+		cfg.FailureThreshold,
+		initialStatus,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.jitter = cfg.Jitter
+
+	return p, nil
+}
+
+func NewTCP(address string, timeout, initialDelay, period time.Duration, successThreshold, failureThreshold int, initialStatus Status) (*tcpProbe, error) {
+	t := &tcpProbe{
+		address: address,
+	}
+	t.runner = newRunner(t.check, initialDelay, period, timeout, successThreshold, failureThreshold, initialStatus)
+
+	return t, nil
+}
+
+var _ Interface = &tcpProbe{}
+
+// tcpProbe succeeds if a tcp connection to the address can be established, like the kubernetes tcpSocket probe.
+type tcpProbe struct {
+	*runner
+	address string
+}
+
+func (t *tcpProbe) check(ctx context.Context) (int, error) {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", t.address)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to connect to %s", t.address)
+	}
+
+	return 0, conn.Close()
+}
@@ -0,0 +1,52 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_tcpProbe_check(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	openAddr := listener.Addr().String()
+	defer listener.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %s", err)
+	}
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{
+			name:    "ok_listening",
+			address: openAddr,
+			wantErr: false,
+		},
+		{
+			name:    "err_refused",
+			address: closedAddr,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewTCP(tt.address, time.Second, 0, time.Second, 1, 1, Failure)
+			if err != nil {
+				t.Fatalf("NewTCP() error = %v", err)
+			}
+			if _, err := p.check(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("tcpProbe.check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package process
+
+import (
+	"context"
+	"log"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NewAttachHandler creates a handler for an already running process which was not started by this tool.
+func NewAttachHandler(pid int) *attachHandler {
+	return &attachHandler{
+		notifier: notifier{
+			subscribers: make([]chan Status, 0),
+			status:      Exited,
+		},
+		cCh:    make(chan Signal),
+		done:   make(chan struct{}),
+		pid:    pid,
+		report: NewReport(),
+	}
+}
+
+var _ Handler = &attachHandler{}
+
+type attachHandler struct {
+	notifier
+	cCh    chan Signal
+	done   chan struct{}
+	pid    int
+	report *Report
+}
+
+// Start monitors the process until it exits. Cancelling the context stops monitoring but leaves the process alone.
+// The exit code of the process is not available as it is not a child of this process.
+func (a *attachHandler) Start(ctx context.Context) error {
+	defer close(a.done)
+	defer a.closeStatus()
+
+	proc, err := os.FindProcess(a.pid)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find process pid=%d", a.pid)
+	}
+
+	if err := proc.Signal(syscall.Signal(0)); err != nil {
+		return errors.Wrapf(err, "process pid=%d is not running", a.pid)
+	}
+
+	a.report.recordPID(a.pid)
+
+	go trackDescendants(ctx, a.pid, a.report)
+
+	exitCh := make(chan struct{})
+	go func() {
+		waitForExit(a.pid)
+		close(exitCh)
+	}()
+
+	a.setStatus(Running)
+
+	for {
+		select {
+		case <-exitCh:
+			a.report.recordExit(time.Now(), -1, "")
+			a.setStatus(Exited)
+			return nil
+		case sig := <-a.cCh:
+			a.report.recordSignal(time.Now())
+			if sig == SignalKill {
+				sampleDescendants(a.pid, a.report)
+				a.report.recordKill(time.Now())
+			}
+			if err := signalProcess(proc, sig); err != nil {
+				log.Printf("failed to send signal %s to process pid=%d: %s", sig, a.pid, err)
+			}
+		case <-ctx.Done():
+			log.Printf("stopped monitoring process pid=%d: %s", a.pid, ctx.Err())
+			return nil
+		}
+	}
+}
+
+// NotifyOutput closes the channel right away, the output of a process not started by this tool is not available.
+func (a *attachHandler) NotifyOutput(oCh chan OutputLine) {
+	close(oCh)
+}
+
+// NotifyState closes the channel right away, a process not started by this tool has no notify socket.
+func (a *attachHandler) NotifyState(nCh chan Notification) {
+	close(nCh)
+}
+
+func (a *attachHandler) Report() *Report {
+	return a.report
+}
+
+func (a *attachHandler) Signal(signal Signal) {
+	select {
+	case a.cCh <- signal:
+	case <-a.done:
+		log.Printf("process pid=%d is no longer monitored, signal %s dropped", a.pid, signal)
+	}
+}
@@ -0,0 +1,80 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"syscall"
+	"time"
+)
+
+// sysPidfdOpen is the number of the pidfd_open syscall, which is the same on all architectures.
+const sysPidfdOpen = 434
+
+// waitForExit blocks until the process exits. It uses a pidfd where supported by the kernel
+// and falls back to polling /proc otherwise.
+func waitForExit(pid int) {
+	if err := waitForPidfd(pid); err != nil {
+		log.Printf("unable to wait for process pid=%d by pidfd, falling back to polling: %s", pid, err)
+		pollProc(pid)
+	}
+}
+
+func waitForPidfd(pid int) error {
+	fd, _, errno := syscall.Syscall(sysPidfdOpen, uintptr(pid), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	defer syscall.Close(int(fd))
+
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(epfd)
+
+	event := syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(fd)}
+	if err := syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, int(fd), &event); err != nil {
+		return err
+	}
+
+	events := make([]syscall.EpollEvent, 1)
+	for {
+		n, err := syscall.EpollWait(epfd, events, -1)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return nil
+		}
+	}
+}
+
+// pollProc waits until /proc/<pid> is gone or the process became a zombie.
+func pollProc(pid int) {
+	for {
+		stat, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil || isZombie(stat) {
+			return
+		}
+
+		<-time.After(10 * time.Millisecond)
+	}
+}
+
+// isZombie reports whether the state in the contents of /proc/<pid>/stat is zombie or dead.
+// The state follows the command name, which is enclosed in parentheses and may contain spaces.
+func isZombie(stat []byte) bool {
+	pos := bytes.LastIndexByte(stat, ')')
+	if pos < 0 || pos+2 >= len(stat) {
+		return false
+	}
+
+	state := stat[pos+2]
+
+	return state == 'Z' || state == 'X'
+}
@@ -0,0 +1,24 @@
+package process
+
+import "testing"
+
+func Test_isZombie(t *testing.T) {
+	tests := []struct {
+		name string
+		stat string
+		want bool
+	}{
+		{name: "running", stat: "42 (node) S 1 42 42 0 -1", want: false},
+		{name: "zombie", stat: "42 (node) Z 1 42 42 0 -1", want: true},
+		{name: "dead", stat: "42 (node) X 1 42 42 0 -1", want: true},
+		{name: "command_with_parentheses", stat: "42 (my (app) Z) R 1 42 42 0 -1", want: false},
+		{name: "truncated", stat: "42 (node)", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isZombie([]byte(tt.stat)); got != tt.want {
+				t.Errorf("isZombie() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
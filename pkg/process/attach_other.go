@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// waitForExit blocks until the process exits by polling for its existence.
+func waitForExit(pid int) {
+	for {
+		proc, err := os.FindProcess(pid)
+		if err != nil {
+			return
+		}
+
+		if err := proc.Signal(syscall.Signal(0)); err != nil {
+			return
+		}
+
+		<-time.After(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,61 @@
+package process
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// readEnvFile reads KEY=VALUE variables from a file, see parseEnv.
+func readEnvFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open env file")
+	}
+	defer f.Close()
+
+	env, err := parseEnv(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid env file %s", path)
+	}
+
+	return env, nil
+}
+
+// parseEnv reads KEY=VALUE lines like docker and systemd env files. Empty lines and comments are skipped,
+// an export prefix and quotes around the value are removed.
+func parseEnv(r io.Reader) ([]string, error) {
+	env := make([]string, 0)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || len(key) == 0 {
+			return nil, errors.Errorf("line %d is not of the form KEY=VALUE", n)
+		}
+
+		value := strings.TrimSpace(parts[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		env = append(env, key+"="+value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
@@ -0,0 +1,43 @@
+package process
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			content: "# database\nDB_HOST=localhost\n\nexport DB_USER=app\nGREETING=\"hello world\"\nQUOTED='a=b'\nEMPTY=\n",
+			want:    []string{"DB_HOST=localhost", "DB_USER=app", "GREETING=hello world", "QUOTED=a=b", "EMPTY="},
+		},
+		{
+			name:    "err_missing_value",
+			content: "DB_HOST=localhost\nDB_USER\n",
+			wantErr: true,
+		},
+		{
+			name:    "err_missing_key",
+			content: "=value\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEnv(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
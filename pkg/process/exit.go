@@ -0,0 +1,31 @@
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+// exitCode returns the exit code of the process, or 128+n if it was terminated by signal n.
+func exitCode(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+
+	return state.ExitCode()
+}
+
+// terminatingSignal returns the name of the signal which terminated the process, if any.
+func terminatingSignal(state *os.ProcessState) string {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+
+	for name, sig := range signals {
+		if sig == status.Signal() {
+			return string(name)
+		}
+	}
+
+	return status.Signal().String()
+}
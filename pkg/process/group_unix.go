@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package process
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// sysProcAttr starts the process in its own process group, so it can be signalled along with its descendants.
+func sysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalGroup sends the signal to all processes in the process group led by pid.
+func signalGroup(pid int, signal Signal) error {
+	sig, ok := signals[signal].(syscall.Signal)
+	if !ok {
+		return errors.Errorf("unsupported signal %q", signal)
+	}
+
+	return syscall.Kill(-pid, sig)
+}
@@ -0,0 +1,20 @@
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+func sysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// signalGroup falls back to signal the process only, as there are no process groups on windows.
+func signalGroup(pid int, signal Signal) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+
+	return signalProcess(proc, signal)
+}
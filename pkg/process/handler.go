@@ -9,8 +9,7 @@ import (
 
 	"time"
 
-	"syscall"
-
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/cli/check-graceful-shutdown/cmd/options"
 	"github.com/pkg/errors"
 )
 
@@ -18,6 +17,11 @@ type Handler interface {
 	Start(ctx context.Context) error
 	Signal(signal Signal)
 	Notify(sCh chan Status)
+	// NotifyOutput subscribes to the lines written by the process. The channel is closed when the output ended.
+	NotifyOutput(oCh chan OutputLine)
+	// NotifyState subscribes to the sd_notify notifications of the process. The channel is closed when the process exited.
+	NotifyState(nCh chan Notification)
+	Report() *Report
 }
 
 type Status string
@@ -27,97 +31,229 @@ const (
 	Exited         = "exited"
 )
 
-type Signal string
+func NewHandlerForConfig(cfg options.ProcessConfig) *handler {
+	h := NewHandler(cfg.Command, cfg.Arguments...)
+	h.signalGroup = cfg.SignalProcessGroup
+	h.stdoutFile = cfg.StdoutFile
+	h.stderrFile = cfg.StderrFile
+	h.prefixOutput = cfg.PrefixOutput
+	h.notifySocket = cfg.NotifySocket
+	h.env = cfg.Env
+	h.envFile = cfg.EnvFile
+	h.dir = cfg.WorkingDir
 
-const (
-	SignalTerminate Signal = "terminate"
-	SignalKill             = "kill"
-)
+	return h
+}
 
 func NewHandler(cmd string, args ...string) *handler {
 	return &handler{
-		subscribers: make([]chan Status, 0),
-		cCh:         make(chan Signal),
-		cmd:         cmd,
-		args:        args,
-		status:      Exited,
+		notifier: notifier{
+			subscribers: make([]chan Status, 0),
+			status:      Exited,
+		},
+		cCh:    make(chan Signal),
+		done:   make(chan struct{}),
+		cmd:    cmd,
+		args:   args,
+		report: NewReport(),
 	}
 }
 
 var _ Handler = &handler{}
 
+// outputDrainTimeout is the time to wait for the remaining output after the process exited.
+const outputDrainTimeout = time.Second
+
+// handler starts the process in its own process group. Signals are sent to the process only,
+// unless signalGroup is set. SIGKILL always hits the whole group, like a container runtime would.
+// The output of the process is captured line by line and passed on to the console.
 type handler struct {
-	status      Status
-	subscribers []chan Status
-	cCh         chan Signal
-	cmd         string
-	args        []string
+	notifier
+	outputNotifier
+	stateNotifier
+	cCh          chan Signal
+	done         chan struct{}
+	cmd          string
+	args         []string
+	signalGroup  bool
+	stdoutFile   string
+	stderrFile   string
+	prefixOutput bool
+	notifySocket bool
+	env          []string
+	envFile      string
+	dir          string
+	report       *Report
 }
 
-// start process, (start listener and traffic flow), receive SIGINT, wait for process to exit, kill process after 30s
+// Start runs the process until it exited. The exit is detected by waiting on the process,
+// so status changes are notified the moment they happen.
 func (h *handler) Start(ctx context.Context) error {
+	defer close(h.done)
+	defer h.closeStatus()
+
 	cmd := exec.CommandContext(ctx, h.cmd, h.args...)
 
-	cmd.Env = os.Environ()
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
+	defer h.closeOutput()
+	defer h.closeState()
+
+	env, err := h.environ()
+	if err != nil {
+		return err
+	}
+	cmd.Env = env
+	cmd.Dir = h.dir
+
+	var notify *notifySocket
+	if h.notifySocket {
+		if notify, err = newNotifySocket(h.observeNotification); err != nil {
+			return err
+		}
+		defer notify.close()
+
+		cmd.Env = append(cmd.Env, "NOTIFY_SOCKET="+notify.path)
+	}
+
+	stdout, err := newOutputCapture(Stdout, os.Stdout, h.prefixOutput, h.stdoutFile, h.observeOutput)
+	if err != nil {
+		return err
+	}
+
+	stderr, err := newOutputCapture(Stderr, os.Stderr, h.prefixOutput, h.stderrFile, h.observeOutput)
+	if err != nil {
+		stdout.abort()
+		return err
+	}
+
+	cmd.Stdout = stdout.writer()
+	cmd.Stderr = stderr.writer()
+	cmd.SysProcAttr = sysProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		stdout.abort()
+		stderr.abort()
+		return errors.Wrap(err, "command execution failed")
+	}
+
+	stdout.start()
+	stderr.start()
+	if notify != nil {
+		notify.start()
+	}
+
+	pid := cmd.Process.Pid
+	h.report.recordPID(pid)
+	h.setStatus(Running)
+
+	exitCh := make(chan struct{})
+
+	go trackDescendants(ctx, pid, h.report)
 
 	go func() {
 		for {
 			select {
-			case <-time.After(time.Millisecond * 100):
-				if cmd.ProcessState != nil && cmd.ProcessState.Exited() {
-					h.setStatus(Exited)
-				} else {
-					h.setStatus(Running)
-				}
 			case sig := <-h.cCh:
-				switch sig {
-				case SignalTerminate:
-					if cmd.ProcessState == nil || !cmd.ProcessState.Exited() {
-						if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
-							log.Printf("failed to send signal %s to process pid=%d: %s", SignalTerminate, cmd.Process.Pid, err)
-						}
-					}
-				case SignalKill:
-					if cmd.ProcessState == nil || !cmd.ProcessState.Exited() {
-						if err := cmd.Process.Kill(); err != nil {
-							log.Printf("failed to kill process pid=%d: %s", cmd.Process.Pid, err)
-						}
-					}
+				if err := h.signal(cmd.Process, sig); err != nil {
+					log.Printf("failed to send signal %s to process pid=%d: %s", sig, pid, err)
+				}
+			case <-ctx.Done():
+				// the context only kills the process itself, make sure no descendants are left behind
+				if err := signalGroup(pid, SignalKill); err != nil {
+					log.Printf("failed to kill process group pid=%d: %s", pid, err)
 				}
+				<-exitCh
+				return
+			case <-exitCh:
+				return
 			}
 		}
 	}()
 
-	if err := cmd.Run(); err != nil {
+	// a non-zero exit is an outcome of the check, not a failure to handle the process
+	err = cmd.Wait()
+	exitedAt := time.Now()
+	close(exitCh)
+
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		stdout.wait(outputDrainTimeout)
+		stderr.wait(outputDrainTimeout)
 		return errors.Wrap(err, "command execution failed")
 	}
 
+	h.report.recordExit(exitedAt, exitCode(cmd.ProcessState), terminatingSignal(cmd.ProcessState))
+
+	// like a container runtime, kill whatever is left of the process group once the process exited,
+	// including descendants which were not observed yet
+	alive := sampleDescendants(pid, h.report)
+	if alive {
+		h.report.recordKill(time.Now())
+	}
+	if err := signalGroup(pid, SignalKill); err != nil && alive {
+		log.Printf("failed to kill remaining process group pid=%d: %s", pid, err)
+	}
+
+	stdout.wait(outputDrainTimeout)
+	stderr.wait(outputDrainTimeout)
+	if notify != nil {
+		notify.close()
+	}
+
+	h.setStatus(Exited)
+
 	return nil
 }
 
 func (h *handler) Signal(signal Signal) {
-	h.cCh <- signal
+	select {
+	case h.cCh <- signal:
+	case <-h.done:
+		log.Printf("process is no longer running, signal %s dropped", signal)
+	}
 }
 
-func (h *handler) Notify(sCh chan Status) {
-	h.subscribers = append(h.subscribers, sCh)
+func (h *handler) Report() *Report {
+	return h.report
 }
 
-func (h *handler) setStatus(status Status) {
-	if h.status == status {
-		return
+// environ returns the environment of this process extended by the variables of the env file and
+// the additional variables. Later definitions of a variable take precedence.
+func (h *handler) environ() ([]string, error) {
+	env := os.Environ()
+
+	if len(h.envFile) != 0 {
+		fileEnv, err := readEnvFile(h.envFile)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, fileEnv...)
 	}
 
-	h.status = status
-	h.notifySubscribers(status)
+	return append(env, h.env...), nil
+}
+
+func (h *handler) observeNotification(notification Notification) {
+	h.report.recordNotification(notification)
+	h.notifyState(notification)
+}
+
+func (h *handler) observeOutput(line OutputLine) {
+	h.report.recordOutput(line)
+	h.notifyOutput(line)
 }
 
-func (h *handler) notifySubscribers(status Status) {
-	for _, sCh := range h.subscribers {
-		go func(ch chan Status) {
-			ch <- status
-		}(sCh)
+func (h *handler) signal(proc *os.Process, signal Signal) error {
+	h.report.recordSignal(time.Now())
+
+	if signal == SignalKill {
+		sampleDescendants(proc.Pid, h.report)
+		h.report.recordKill(time.Now())
+
+		return signalGroup(proc.Pid, signal)
 	}
+
+	if h.signalGroup {
+		return signalGroup(proc.Pid, signal)
+	}
+
+	return signalProcess(proc, signal)
 }
@@ -0,0 +1,64 @@
+//go:build !windows
+// +build !windows
+
+package process
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_handler_Start(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		signal   Signal
+		wantCode int
+	}{
+		{name: "ok_exit_code", args: []string{"-c", "exit 3"}, wantCode: 3},
+		{name: "ok_terminated", args: []string{"-c", "sleep 10"}, signal: SignalTerminate, wantCode: 143},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler("/bin/sh", tt.args...)
+			sCh := make(chan Status)
+			h.Notify(sCh)
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- h.Start(context.Background())
+			}()
+
+			if s := <-sCh; s != Running {
+				t.Fatalf("first status = %s, want %s", s, Running)
+			}
+			if len(tt.signal) > 0 {
+				h.Signal(tt.signal)
+			}
+
+			select {
+			case s := <-sCh:
+				if s != Exited {
+					t.Fatalf("second status = %s, want %s", s, Exited)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("exit was not notified")
+			}
+
+			if err := <-errCh; err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			exit, ok := h.Report().Exit()
+			if !ok {
+				t.Fatalf("Report().Exit() ok = false")
+			}
+			if exit.Code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", exit.Code, tt.wantCode)
+			}
+
+			// the process is gone, signaling must not block
+			h.Signal(SignalTerminate)
+		})
+	}
+}
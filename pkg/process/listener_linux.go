@@ -0,0 +1,119 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// tcpListen is the state of a listening socket in /proc/net/tcp.
+const tcpListen = "0A"
+
+// FindListenerOwners resolves the pids of all processes holding a socket listening on the tcp port.
+// Processes of other users are only visible with sufficient privileges.
+func FindListenerOwners(port int) ([]int, error) {
+	return findListenerOwners("/proc", port)
+}
+
+// findListenerOwners resolves the listener owners from the proc filesystem mounted at root.
+func findListenerOwners(root string, port int) ([]int, error) {
+	inodes := make(map[string]bool)
+	for _, table := range []string{"net/tcp", "net/tcp6"} {
+		data, err := ioutil.ReadFile(filepath.Join(root, table))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, inode := range parseListeningInodes(data, port) {
+			inodes[inode] = true
+		}
+	}
+
+	if len(inodes) == 0 {
+		return nil, errors.Errorf("no listener on port %d found", port)
+	}
+
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make([]int, 0)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		if ownsSocket(root, pid, inodes) {
+			owners = append(owners, pid)
+		}
+	}
+
+	if len(owners) == 0 {
+		return nil, errors.Errorf("no process owning the listener on port %d found", port)
+	}
+
+	sort.Ints(owners)
+
+	return owners, nil
+}
+
+func ownsSocket(root string, pid int, inodes map[string]bool) bool {
+	dir := filepath.Join(root, strconv.Itoa(pid), "fd")
+
+	fds, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(dir, fd.Name()))
+		if err != nil {
+			continue
+		}
+
+		if strings.HasPrefix(link, "socket:[") && inodes[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseListeningInodes returns the inodes of listening sockets on the port from the contents of /proc/net/tcp{,6}.
+func parseListeningInodes(data []byte, port int) []string {
+	inodes := make([]string, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListen {
+			continue
+		}
+
+		pos := strings.LastIndexByte(fields[1], ':')
+		if pos < 0 {
+			continue
+		}
+
+		localPort, err := strconv.ParseUint(fields[1][pos+1:], 16, 16)
+		if err != nil || int(localPort) != port {
+			continue
+		}
+
+		inodes = append(inodes, fields[9])
+	}
+
+	return inodes
+}
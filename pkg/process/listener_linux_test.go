@@ -0,0 +1,99 @@
+package process
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_parseListeningInodes(t *testing.T) {
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 4711 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F91 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 4712 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000  1000        0 4713 1 0000000000000000 20 4 30 10 -1
+`
+
+	tests := []struct {
+		name string
+		port int
+		want []string
+	}{
+		{name: "ok_listening", port: 8080, want: []string{"4711"}},
+		{name: "ok_other_port", port: 8081, want: []string{"4712"}},
+		{name: "none", port: 9090, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseListeningInodes([]byte(table), tt.port); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseListeningInodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findListenerOwners(t *testing.T) {
+	root, err := ioutil.TempDir("", "proc")
+	if err != nil {
+		t.Fatalf("failed to create proc dir: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 4711 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F91 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 4712 1 0000000000000000 100 0 0 10 0
+`
+	files := map[string]string{
+		"net/tcp":     table,
+		"self/status": "",
+	}
+	// the wrapper pid=100 holds no socket, its child pid=101 and an unrelated pid=300 own the listener
+	links := map[string]string{
+		"100/fd/0": "/dev/null",
+		"101/fd/3": "socket:[4711]",
+		"200/fd/3": "socket:[9999]",
+		"300/fd/7": "socket:[4711]",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %s", name, err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %s", name, err)
+		}
+	}
+	for name, target := range links {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %s", name, err)
+		}
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatalf("failed to link %s: %s", name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		port    int
+		want    []int
+		wantErr bool
+	}{
+		{name: "ok", port: 8080, want: []int{101, 300}},
+		{name: "err_no_owner", port: 8081, wantErr: true},
+		{name: "err_no_listener", port: 9090, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findListenerOwners(root, tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findListenerOwners() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findListenerOwners() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
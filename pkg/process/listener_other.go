@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import "github.com/pkg/errors"
+
+// FindListenerOwners requires /proc and is not supported on this platform.
+func FindListenerOwners(port int) ([]int, error) {
+	return nil, errors.New("resolving listener owners is only supported on linux")
+}
@@ -0,0 +1,66 @@
+package process
+
+import "sync"
+
+// statusQueueSize covers every status change of a process, which is started and exits once.
+const statusQueueSize = 2
+
+// notifier tracks the status of a process and informs subscribers about changes.
+// Changes are delivered to each subscriber in the order they happened, none is dropped.
+type notifier struct {
+	mu          sync.Mutex
+	status      Status
+	closed      bool
+	subscribers []chan Status
+}
+
+// Notify subscribes to status changes. Once the process was handled, the subscriber gets the final status only.
+func (n *notifier) Notify(sCh chan Status) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	queue := make(chan Status, statusQueueSize)
+	go func() {
+		for status := range queue {
+			sCh <- status
+		}
+	}()
+
+	if n.closed {
+		queue <- n.status
+		close(queue)
+		return
+	}
+
+	n.subscribers = append(n.subscribers, queue)
+}
+
+func (n *notifier) setStatus(status Status) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed || n.status == status {
+		return
+	}
+
+	n.status = status
+	for _, queue := range n.subscribers {
+		queue <- status
+	}
+}
+
+// closeStatus ends the notifications once the process was handled. Pending changes are still delivered.
+func (n *notifier) closeStatus() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return
+	}
+
+	n.closed = true
+	for _, queue := range n.subscribers {
+		close(queue)
+	}
+	n.subscribers = nil
+}
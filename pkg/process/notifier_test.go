@@ -0,0 +1,41 @@
+package process
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_notifier(t *testing.T) {
+	n := &notifier{status: Exited}
+
+	early := make(chan Status)
+	n.Notify(early)
+
+	// the subscriber does not consume yet, no change may be dropped
+	n.setStatus(Running)
+	n.setStatus(Exited)
+	n.closeStatus()
+
+	late := make(chan Status)
+	n.Notify(late)
+
+	for _, tt := range []struct {
+		name string
+		sCh  chan Status
+		want []Status
+	}{
+		{name: "early", sCh: early, want: []Status{Running, Exited}},
+		{name: "late", sCh: late, want: []Status{Exited}},
+	} {
+		for _, want := range tt.want {
+			select {
+			case got := <-tt.sCh:
+				if got != want {
+					t.Errorf("%s subscriber got %s, want %s", tt.name, got, want)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("%s subscriber did not get %s", tt.name, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package process
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification is a single assignment the process sent via sd_notify, e.g. READY=1 or STATUS=Processing requests.
+type Notification struct {
+	Time  time.Time
+	Key   string
+	Value string
+}
+
+func (n Notification) String() string {
+	return n.Key + "=" + n.Value
+}
+
+// parseNotifications splits a sd_notify message into its newline separated assignments.
+func parseNotifications(t time.Time, msg string) []Notification {
+	notifications := make([]Notification, 0)
+
+	for _, line := range strings.Split(msg, "\n") {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			continue
+		}
+
+		notifications = append(notifications, Notification{Time: t, Key: parts[0], Value: parts[1]})
+	}
+
+	return notifications
+}
+
+// stateNotifier passes the sd_notify notifications of the process on to subscribers.
+// The channels are closed when the process exited.
+type stateNotifier struct {
+	mu          sync.Mutex
+	subscribers []chan Notification
+}
+
+func (n *stateNotifier) NotifyState(nCh chan Notification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.subscribers = append(n.subscribers, nCh)
+}
+
+func (n *stateNotifier) notifyState(notification Notification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, nCh := range n.subscribers {
+		nCh <- notification
+	}
+}
+
+func (n *stateNotifier) closeState() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, nCh := range n.subscribers {
+		close(nCh)
+	}
+	n.subscribers = nil
+}
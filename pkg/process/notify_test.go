@@ -0,0 +1,43 @@
+package process
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_parseNotifications(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		msg  string
+		want []Notification
+	}{
+		{
+			name: "ok_single",
+			msg:  "READY=1",
+			want: []Notification{{Time: now, Key: "READY", Value: "1"}},
+		},
+		{
+			name: "ok_multiple",
+			msg:  "STOPPING=1\nSTATUS=Closing connections: a=b\n",
+			want: []Notification{
+				{Time: now, Key: "STOPPING", Value: "1"},
+				{Time: now, Key: "STATUS", Value: "Closing connections: a=b"},
+			},
+		},
+		{
+			name: "ok_invalid_ignored",
+			msg:  "garbage\n=1\nMAINPID=42",
+			want: []Notification{{Time: now, Key: "MAINPID", Value: "42"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseNotifications(now, tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNotifications() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+//go:build !windows
+// +build !windows
+
+package process
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// notifyDrainTimeout is the time to read remaining notifications after the process exited.
+const notifyDrainTimeout = 100 * time.Millisecond
+
+func newNotifySocket(observe func(Notification)) (*notifySocket, error) {
+	dir, err := ioutil.TempDir("", "check-graceful-shutdown-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create directory for notify socket")
+	}
+
+	path := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, errors.Wrap(err, "failed to create notify socket")
+	}
+
+	return &notifySocket{
+		dir:     dir,
+		path:    path,
+		conn:    conn,
+		observe: observe,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// notifySocket receives sd_notify messages of the process, like systemd does for Type=notify services.
+type notifySocket struct {
+	dir     string
+	path    string
+	conn    *net.UnixConn
+	observe func(Notification)
+	done    chan struct{}
+	started bool
+	once    sync.Once
+}
+
+func (s *notifySocket) start() {
+	s.started = true
+	go s.read()
+}
+
+// close reads the notifications already sent and removes the socket.
+func (s *notifySocket) close() {
+	s.once.Do(func() {
+		if s.started {
+			s.conn.SetReadDeadline(time.Now().Add(notifyDrainTimeout))
+			<-s.done
+		}
+		s.conn.Close()
+		os.RemoveAll(s.dir)
+	})
+}
+
+func (s *notifySocket) read() {
+	defer close(s.done)
+
+	buf := make([]byte, 4096)
+	for {
+		n, _, err := s.conn.ReadFromUnix(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+				log.Printf("failed to read from notify socket: %s", err)
+			}
+			return
+		}
+
+		for _, notification := range parseNotifications(time.Now(), string(buf[:n])) {
+			s.observe(notification)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package process
+
+import "github.com/pkg/errors"
+
+type notifySocket struct {
+	path string
+}
+
+func newNotifySocket(observe func(Notification)) (*notifySocket, error) {
+	return nil, errors.New("notify socket is not supported on windows")
+}
+
+func (s *notifySocket) start() {}
+
+func (s *notifySocket) close() {}
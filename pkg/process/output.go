@@ -0,0 +1,194 @@
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Stream is an output stream of the process.
+type Stream string
+
+const (
+	Stdout Stream = "stdout"
+	Stderr Stream = "stderr"
+)
+
+// OutputLine is a line written by the process, timestamped when it was read.
+type OutputLine struct {
+	Time   time.Time
+	Stream Stream
+	Text   string
+}
+
+func newOutputCapture(stream Stream, console io.Writer, prefix bool, file string, observe func(OutputLine)) (*outputCapture, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create pipe for %s", stream)
+	}
+
+	c := &outputCapture{
+		stream:  stream,
+		console: console,
+		prefix:  prefix,
+		observe: observe,
+		r:       r,
+		w:       w,
+		done:    make(chan struct{}),
+	}
+
+	if len(file) != 0 {
+		f, err := os.Create(file)
+		if err != nil {
+			r.Close()
+			w.Close()
+			return nil, errors.Wrapf(err, "failed to create %s file", stream)
+		}
+		c.tee = f
+	}
+
+	return c, nil
+}
+
+// outputCapture reads the output of the process line by line. Lines are passed to observe,
+// written to the console and, if configured, to a file.
+type outputCapture struct {
+	stream  Stream
+	console io.Writer
+	prefix  bool
+	tee     *os.File
+	observe func(OutputLine)
+	r       *os.File
+	w       *os.File
+	done    chan struct{}
+}
+
+// writer is passed to the process. The process writes directly into the pipe, so waiting for the
+// process does not depend on the output being consumed.
+func (c *outputCapture) writer() *os.File {
+	return c.w
+}
+
+// start reads the output until every process holding the pipe closed it.
+func (c *outputCapture) start() {
+	c.w.Close()
+
+	go c.read()
+}
+
+// abort releases the pipe if the process could not be started.
+func (c *outputCapture) abort() {
+	c.w.Close()
+	c.r.Close()
+	if c.tee != nil {
+		c.tee.Close()
+	}
+}
+
+// wait blocks until the output was read completely or the timeout passed. The latter happens if a
+// process outside of the process group still holds the pipe.
+func (c *outputCapture) wait(timeout time.Duration) {
+	select {
+	case <-c.done:
+	case <-time.After(timeout):
+		log.Printf("%s of the process still open after %s, stop reading", c.stream, timeout)
+		c.r.Close()
+		<-c.done
+	}
+}
+
+func (c *outputCapture) read() {
+	defer close(c.done)
+	defer c.r.Close()
+	if c.tee != nil {
+		defer c.tee.Close()
+	}
+
+	reader := bufio.NewReader(c.r)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			c.handle(strings.TrimRight(line, "\r\n"))
+		}
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, os.ErrClosed) {
+				log.Printf("failed to read %s of the process: %s", c.stream, err)
+			}
+			return
+		}
+	}
+}
+
+func (c *outputCapture) handle(text string) {
+	c.observe(OutputLine{Time: time.Now(), Stream: c.stream, Text: text})
+
+	if c.tee != nil {
+		fmt.Fprintln(c.tee, text)
+	}
+
+	if c.prefix {
+		fmt.Fprintf(c.console, "[%s] %s\n", c.stream, text)
+	} else {
+		fmt.Fprintln(c.console, text)
+	}
+}
+
+// outputQueueSize is the number of lines buffered for each subscriber of the output.
+const outputQueueSize = 1024
+
+// outputNotifier passes the output of the process on to subscribers. Lines are queued per subscriber, so a slow
+// subscriber does not block the process writing further output. Lines exceeding the queue are dropped, they are
+// still recorded in the report. The channels are closed when the output ended.
+type outputNotifier struct {
+	mu          sync.Mutex
+	subscribers []chan OutputLine
+}
+
+func (n *outputNotifier) NotifyOutput(oCh chan OutputLine) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	queue := make(chan OutputLine, outputQueueSize)
+	go func() {
+		defer close(oCh)
+
+		for line := range queue {
+			oCh <- line
+		}
+	}()
+
+	n.subscribers = append(n.subscribers, queue)
+}
+
+func (n *outputNotifier) notifyOutput(line OutputLine) {
+	n.mu.Lock()
+	queues := make([]chan OutputLine, len(n.subscribers))
+	copy(queues, n.subscribers)
+	n.mu.Unlock()
+
+	for _, queue := range queues {
+		select {
+		case queue <- line:
+		default:
+			log.Printf("output subscriber falls behind, dropped %s line %q", line.Stream, line.Text)
+		}
+	}
+}
+
+// closeOutput is called once the output was read completely, no more lines are notified afterwards.
+func (n *outputNotifier) closeOutput() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, queue := range n.subscribers {
+		close(queue)
+	}
+	n.subscribers = nil
+}
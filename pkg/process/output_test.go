@@ -0,0 +1,128 @@
+package process
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_outputCapture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "output")
+	if err != nil {
+		t.Fatalf("failed to create dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name        string
+		prefix      bool
+		file        string
+		wantConsole string
+	}{
+		{name: "ok_plain", wantConsole: "started\nlistening\nno newline\n"},
+		{name: "ok_prefixed", prefix: true, wantConsole: "[stderr] started\n[stderr] listening\n[stderr] no newline\n"},
+		{name: "ok_tee", file: filepath.Join(dir, "stderr.log"), wantConsole: "started\nlistening\nno newline\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			console := new(bytes.Buffer)
+			observed := make([]string, 0)
+
+			c, err := newOutputCapture(Stderr, console, tt.prefix, tt.file, func(line OutputLine) {
+				if line.Stream != Stderr {
+					t.Errorf("observed stream %s, want %s", line.Stream, Stderr)
+				}
+				observed = append(observed, line.Text)
+			})
+			if err != nil {
+				t.Fatalf("newOutputCapture() error = %v", err)
+			}
+
+			if _, err := c.writer().WriteString("started\r\nlistening\nno newline"); err != nil {
+				t.Fatalf("failed to write output: %s", err)
+			}
+
+			c.start()
+			c.wait(time.Second)
+
+			if want := []string{"started", "listening", "no newline"}; !reflect.DeepEqual(observed, want) {
+				t.Errorf("observed lines = %q, want %q", observed, want)
+			}
+			if got := console.String(); got != tt.wantConsole {
+				t.Errorf("console = %q, want %q", got, tt.wantConsole)
+			}
+
+			if len(tt.file) != 0 {
+				data, err := ioutil.ReadFile(tt.file)
+				if err != nil {
+					t.Fatalf("failed to read tee file: %s", err)
+				}
+				if got, want := string(data), "started\nlistening\nno newline\n"; got != want {
+					t.Errorf("tee file = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_outputCapture_wait(t *testing.T) {
+	c, err := newOutputCapture(Stdout, ioutil.Discard, false, "", func(OutputLine) {})
+	if err != nil {
+		t.Fatalf("newOutputCapture() error = %v", err)
+	}
+	// the writer stays open, like held by a process outside of the process group
+	defer c.w.Close()
+
+	go c.read()
+
+	start := time.Now()
+	c.wait(100 * time.Millisecond)
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Errorf("outputCapture.wait() returned after %s, want 100ms", elapsed)
+	}
+}
+
+func Test_outputNotifier(t *testing.T) {
+	n := new(outputNotifier)
+
+	slow := make(chan OutputLine)
+	n.NotifyOutput(slow)
+
+	lines := outputQueueSize + 10
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < lines; i++ {
+			n.notifyOutput(OutputLine{Stream: Stdout, Text: strconv.Itoa(i)})
+		}
+	}()
+
+	// the subscriber does not consume, the reader of the output must not be blocked
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("notifyOutput() blocked by a slow subscriber")
+	}
+
+	n.closeOutput()
+
+	var got int
+	for line := range slow {
+		if line.Text != strconv.Itoa(got) {
+			t.Fatalf("line %d = %q, want lines in order", got, line.Text)
+		}
+		got++
+	}
+
+	// the forwarding goroutine may hold one line in addition to the queue
+	if got < outputQueueSize || got > outputQueueSize+1 {
+		t.Errorf("received %d lines, want the %d queued", got, outputQueueSize)
+	}
+}
@@ -0,0 +1,307 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mrcrgl/check-graceful-shutdown/pkg/timeline"
+)
+
+func NewReport() *Report {
+	return &Report{
+		descendants:   make(map[int]*Descendant),
+		commands:      make(map[int]string),
+		signalMasks:   make(map[int]SignalMasks),
+		output:        make([]OutputLine, 0),
+		notifications: make([]Notification, 0),
+	}
+}
+
+// Report collects what was observed about the process while it was handled.
+type Report struct {
+	mu            sync.RWMutex
+	pid           int
+	descendants   map[int]*Descendant
+	commands      map[int]string
+	signalMasks   map[int]SignalMasks
+	signaledAt    time.Time
+	killedAt      time.Time
+	exit          *ExitStatus
+	output        []OutputLine
+	notifications []Notification
+}
+
+// ExitStatus describes how the process terminated.
+type ExitStatus struct {
+	Time time.Time
+	// Code is the exit code, 128+n if the process was terminated by signal n like container runtimes
+	// report it, or -1 if unknown.
+	Code int
+	// Signal terminated the process, empty if it exited by itself.
+	Signal string
+	// Killed is set if SIGKILL was sent before the process exited.
+	Killed bool
+	// SinceSignal is the time between the first signal sent and the exit, zero if no signal was sent.
+	SinceSignal time.Duration
+}
+
+// Descendant is a process started by the handled process, directly or transitively.
+type Descendant struct {
+	PID         int
+	PPID        int
+	Command     string
+	FirstSeen   time.Time
+	Exited      time.Time
+	AliveAtKill bool
+}
+
+func (r *Report) recordPID(pid int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pid = pid
+}
+
+func (r *Report) PID() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.pid
+}
+
+// recordSignalMasks records the signal dispositions of a process as long as no signal was sent,
+// so the report reflects the state right before the shutdown.
+func (r *Report) recordSignalMasks(pid int, command string, masks SignalMasks) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.signaledAt.IsZero() {
+		return
+	}
+
+	r.commands[pid] = command
+	r.signalMasks[pid] = masks
+}
+
+// SignalMasks returns the signal dispositions of the process and its descendants before the first signal.
+func (r *Report) SignalMasks() map[int]SignalMasks {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	masks := make(map[int]SignalMasks, len(r.signalMasks))
+	for pid, m := range r.signalMasks {
+		masks[pid] = m
+	}
+
+	return masks
+}
+
+func (r *Report) recordExit(t time.Time, code int, signal string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exit != nil {
+		return
+	}
+
+	r.exit = &ExitStatus{
+		Time:   t,
+		Code:   code,
+		Signal: signal,
+		Killed: !r.killedAt.IsZero(),
+	}
+
+	if !r.signaledAt.IsZero() {
+		r.exit.SinceSignal = t.Sub(r.signaledAt)
+	}
+}
+
+// Exit returns how the process terminated, if it did.
+func (r *Report) Exit() (ExitStatus, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.exit == nil {
+		return ExitStatus{}, false
+	}
+
+	return *r.exit, true
+}
+
+func (r *Report) recordSignal(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.signaledAt.IsZero() {
+		r.signaledAt = t
+	}
+}
+
+func (r *Report) observeDescendant(pid, ppid int, command string, t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.descendants[pid]; ok {
+		return
+	}
+
+	r.descendants[pid] = &Descendant{PID: pid, PPID: ppid, Command: command, FirstSeen: t}
+}
+
+// aliveDescendants returns the pids of all descendants not known to have exited.
+func (r *Report) aliveDescendants() []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pids := make([]int, 0)
+	for pid, d := range r.descendants {
+		if d.Exited.IsZero() {
+			pids = append(pids, pid)
+		}
+	}
+
+	return pids
+}
+
+func (r *Report) recordDescendantExit(pid int, t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if d, ok := r.descendants[pid]; ok && d.Exited.IsZero() {
+		d.Exited = t
+	}
+}
+
+// recordKill marks all descendants which did not exit yet as alive at the time of SIGKILL.
+func (r *Report) recordKill(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.killedAt.IsZero() {
+		return
+	}
+
+	r.killedAt = t
+	for _, d := range r.descendants {
+		if d.Exited.IsZero() {
+			d.AliveAtKill = true
+		}
+	}
+}
+
+func (r *Report) recordOutput(line OutputLine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.output = append(r.output, line)
+}
+
+// Output returns the lines written by the process in the order they were read.
+func (r *Report) Output() []OutputLine {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	output := make([]OutputLine, len(r.output))
+	copy(output, r.output)
+
+	return output
+}
+
+func (r *Report) recordNotification(notification Notification) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications = append(r.notifications, notification)
+}
+
+// Notifications returns the sd_notify notifications of the process in the order they were received.
+func (r *Report) Notifications() []Notification {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notifications := make([]Notification, len(r.notifications))
+	copy(notifications, r.notifications)
+
+	return notifications
+}
+
+// Descendants returns all observed descendants ordered by the time they were first seen.
+func (r *Report) Descendants() []Descendant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	descendants := make([]Descendant, 0, len(r.descendants))
+	for _, d := range r.descendants {
+		descendants = append(descendants, *d)
+	}
+
+	sort.Slice(descendants, func(i, j int) bool {
+		if descendants[i].FirstSeen.Equal(descendants[j].FirstSeen) {
+			return descendants[i].PID < descendants[j].PID
+		}
+		return descendants[i].FirstSeen.Before(descendants[j].FirstSeen)
+	})
+
+	return descendants
+}
+
+func (r *Report) String() string {
+	descendants := r.Descendants()
+
+	buf := bytes.NewBuffer([]byte(""))
+
+	if exit, ok := r.Exit(); ok {
+		fmt.Fprintf(buf, "exit of pid=%d at %s:\n", r.PID(), exit.Time.Format(timeline.TimeFormat))
+		if exit.Code >= 0 {
+			fmt.Fprintf(buf, "\tcode: %d\n", exit.Code)
+		} else {
+			fmt.Fprint(buf, "\tcode: unknown\n")
+		}
+		if len(exit.Signal) != 0 {
+			fmt.Fprintf(buf, "\tterminated by: %s\n", exit.Signal)
+		}
+		if exit.SinceSignal > 0 {
+			fmt.Fprintf(buf, "\ttime from first signal to exit: %s\n", exit.SinceSignal.Round(time.Millisecond))
+		}
+
+		fmt.Fprint(buf, "\n")
+	}
+
+	fmt.Fprintf(buf, "descendants of pid=%d:\n", r.PID())
+	if len(descendants) == 0 {
+		fmt.Fprint(buf, "\tnone observed\n")
+	}
+
+	for _, d := range descendants {
+		fmt.Fprintf(buf, "\tpid=%d ppid=%d (%s) seen at %s, ", d.PID, d.PPID, d.Command, d.FirstSeen.Format(timeline.TimeFormat))
+		switch {
+		case d.AliveAtKill:
+			fmt.Fprint(buf, "still alive at SIGKILL\n")
+		case !d.Exited.IsZero():
+			fmt.Fprintf(buf, "exited at %s\n", d.Exited.Format(timeline.TimeFormat))
+		default:
+			fmt.Fprint(buf, "still alive\n")
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.signalMasks) > 0 {
+		pids := make([]int, 0, len(r.signalMasks))
+		for pid := range r.signalMasks {
+			pids = append(pids, pid)
+		}
+		sort.Ints(pids)
+
+		fmt.Fprint(buf, "SIGTERM disposition before shutdown:\n")
+		for _, pid := range pids {
+			fmt.Fprintf(buf, "\tpid=%d (%s): %s\n", pid, r.commands[pid], r.signalMasks[pid].Disposition(sigTerm))
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,73 @@
+package process
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sigTerm is the number of SIGTERM on all platforms /proc is available on.
+const sigTerm = 15
+
+// SignalMasks are the signal dispositions of a process as listed in /proc/<pid>/status.
+// Bit n-1 of each mask represents signal n.
+type SignalMasks struct {
+	Blocked uint64
+	Ignored uint64
+	Caught  uint64
+}
+
+// Disposition describes how the process treats the signal with the number signum:
+// caught by a handler, ignored or left at the default action, possibly blocked.
+func (sm SignalMasks) Disposition(signum int) string {
+	bit := uint64(1) << uint(signum-1)
+
+	var disposition string
+	switch {
+	case sm.Caught&bit != 0:
+		disposition = "caught"
+	case sm.Ignored&bit != 0:
+		disposition = "ignored"
+	default:
+		disposition = "default"
+	}
+
+	if sm.Blocked&bit != 0 {
+		disposition += ", blocked"
+	}
+
+	return disposition
+}
+
+// parseSignalMasks extracts the SigBlk, SigIgn and SigCgt masks from the contents of /proc/<pid>/status.
+func parseSignalMasks(status string) (SignalMasks, bool) {
+	var masks SignalMasks
+	var found int
+
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		var target *uint64
+		switch fields[0] {
+		case "SigBlk:":
+			target = &masks.Blocked
+		case "SigIgn:":
+			target = &masks.Ignored
+		case "SigCgt:":
+			target = &masks.Caught
+		default:
+			continue
+		}
+
+		value, err := strconv.ParseUint(fields[1], 16, 64)
+		if err != nil {
+			return SignalMasks{}, false
+		}
+		*target = value
+		found++
+	}
+
+	return masks, found == 3
+}
@@ -0,0 +1,42 @@
+package process
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseSignalMasks(t *testing.T) {
+	status := "Name:\tnode\nSigQ:\t0/23960\nSigPnd:\t0000000000000000\nShdPnd:\t0000000000000000\n" +
+		"SigBlk:\t0000000000000000\nSigIgn:\t0000000000001000\nSigCgt:\t0000000180004002\n"
+
+	got, ok := parseSignalMasks(status)
+	if !ok {
+		t.Fatalf("parseSignalMasks() ok = false")
+	}
+
+	want := SignalMasks{Blocked: 0, Ignored: 0x1000, Caught: 0x180004002}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSignalMasks() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSignalMasks_Disposition(t *testing.T) {
+	tests := []struct {
+		name   string
+		masks  SignalMasks
+		signum int
+		want   string
+	}{
+		{name: "caught", masks: SignalMasks{Caught: 1 << 14}, signum: 15, want: "caught"},
+		{name: "ignored", masks: SignalMasks{Ignored: 1 << 14}, signum: 15, want: "ignored"},
+		{name: "default", masks: SignalMasks{Caught: 1 << 1}, signum: 15, want: "default"},
+		{name: "default_blocked", masks: SignalMasks{Blocked: 1 << 14}, signum: 15, want: "default, blocked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.masks.Disposition(tt.signum); got != tt.want {
+				t.Errorf("SignalMasks.Disposition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package process
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Signal string
+
+const (
+	SignalHangup    Signal = "SIGHUP"
+	SignalInterrupt Signal = "SIGINT"
+	SignalQuit      Signal = "SIGQUIT"
+	SignalTerminate Signal = "SIGTERM"
+	SignalUser1     Signal = "SIGUSR1"
+	SignalUser2     Signal = "SIGUSR2"
+	SignalKill      Signal = "SIGKILL"
+)
+
+// ParseSignal resolves a signal by its name, e.g. SIGTERM, TERM or term.
+func ParseSignal(name string) (Signal, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+
+	if _, ok := signals[Signal(name)]; !ok {
+		return "", errors.Errorf("unsupported signal %q", name)
+	}
+
+	return Signal(name), nil
+}
+
+func signalProcess(proc *os.Process, signal Signal) error {
+	sig, ok := signals[signal]
+	if !ok {
+		return errors.Errorf("unsupported signal %q", signal)
+	}
+
+	return proc.Signal(sig)
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+var signals = map[Signal]os.Signal{
+	SignalHangup:    syscall.SIGHUP,
+	SignalInterrupt: syscall.SIGINT,
+	SignalQuit:      syscall.SIGQUIT,
+	SignalTerminate: syscall.SIGTERM,
+	SignalUser1:     syscall.SIGUSR1,
+	SignalUser2:     syscall.SIGUSR2,
+	SignalKill:      syscall.SIGKILL,
+}
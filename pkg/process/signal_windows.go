@@ -0,0 +1,15 @@
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+// signals lists the signals known on windows, even though processes can only be killed there.
+var signals = map[Signal]os.Signal{
+	SignalHangup:    syscall.SIGHUP,
+	SignalInterrupt: syscall.SIGINT,
+	SignalQuit:      syscall.SIGQUIT,
+	SignalTerminate: syscall.SIGTERM,
+	SignalKill:      syscall.SIGKILL,
+}
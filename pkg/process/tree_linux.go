@@ -0,0 +1,147 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
+)
+
+// trackDescendants samples the process tree below pid until the process and all of its
+// descendants exited or the context is done.
+func trackDescendants(ctx context.Context, pid int, report *Report) {
+	for {
+		if !sampleDescendants(pid, report) {
+			return
+		}
+
+		select {
+		case <-time.After(50 * time.Millisecond):
+		case <-ctx.Done():
+			sampleDescendants(pid, report)
+			return
+		}
+	}
+}
+
+// sampleDescendants records new descendants of pid found in /proc and the exit of known ones.
+// Known descendants are followed even after they got reparented, e.g. when their parent exited.
+// It reports whether the process or any of its descendants is still alive.
+func sampleDescendants(pid int, report *Report) bool {
+	now := time.Now()
+
+	procs, err := readProcTable()
+	if err != nil {
+		return false
+	}
+
+	children := make(map[int][]int)
+	for _, p := range procs {
+		children[p.ppid] = append(children[p.ppid], p.pid)
+	}
+
+	queue := children[pid]
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = append(queue[1:], children[next]...)
+
+		p := procs[next]
+		report.observeDescendant(p.pid, p.ppid, p.command, now)
+	}
+
+	alive := false
+	if p, ok := procs[pid]; ok && !p.zombie {
+		alive = true
+		sampleSignalMasks(p, report)
+	}
+
+	for _, known := range report.aliveDescendants() {
+		if p, ok := procs[known]; !ok || p.zombie {
+			report.recordDescendantExit(known, now)
+		} else {
+			alive = true
+			sampleSignalMasks(p, report)
+		}
+	}
+
+	return alive
+}
+
+func sampleSignalMasks(p procEntry, report *Report) {
+	status, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", p.pid))
+	if err != nil {
+		return
+	}
+
+	if masks, ok := parseSignalMasks(string(status)); ok {
+		report.recordSignalMasks(p.pid, p.command, masks)
+	}
+}
+
+type procEntry struct {
+	pid     int
+	ppid    int
+	command string
+	zombie  bool
+}
+
+func readProcTable() (map[int]procEntry, error) {
+	entries, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	procs := make(map[int]procEntry)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		stat, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			continue
+		}
+
+		p, ok := parseStat(stat)
+		if !ok {
+			continue
+		}
+		procs[p.pid] = p
+	}
+
+	return procs, nil
+}
+
+// parseStat extracts pid, command, state and ppid from the contents of /proc/<pid>/stat.
+func parseStat(stat []byte) (procEntry, bool) {
+	open := bytes.IndexByte(stat, '(')
+	closing := bytes.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return procEntry{}, false
+	}
+
+	pid, err := strconv.Atoi(string(bytes.TrimSpace(stat[:open])))
+	if err != nil {
+		return procEntry{}, false
+	}
+
+	fields := bytes.Fields(stat[closing+1:])
+	if len(fields) < 2 {
+		return procEntry{}, false
+	}
+
+	ppid, err := strconv.Atoi(string(fields[1]))
+	if err != nil {
+		return procEntry{}, false
+	}
+
+	return procEntry{
+		pid:     pid,
+		ppid:    ppid,
+		command: string(stat[open+1 : closing]),
+		zombie:  isZombie(stat),
+	}, true
+}
@@ -0,0 +1,44 @@
+package process
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseStat(t *testing.T) {
+	tests := []struct {
+		name   string
+		stat   string
+		want   procEntry
+		wantOk bool
+	}{
+		{
+			name:   "ok_simple",
+			stat:   "42 (node) S 7 42 42 0 -1 4194560",
+			want:   procEntry{pid: 42, ppid: 7, command: "node"},
+			wantOk: true,
+		},
+		{
+			name:   "ok_command_with_spaces_and_parentheses",
+			stat:   "43 (my (worker) 1) Z 42 42 42 0 -1 4194560",
+			want:   procEntry{pid: 43, ppid: 42, command: "my (worker) 1", zombie: true},
+			wantOk: true,
+		},
+		{
+			name:   "err_truncated",
+			stat:   "44 (node) S",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseStat([]byte(tt.stat))
+			if ok != tt.wantOk {
+				t.Fatalf("parseStat() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStat() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import "context"
+
+// trackDescendants is not supported without /proc.
+func trackDescendants(ctx context.Context, pid int, report *Report) {}
+
+func sampleDescendants(pid int, report *Report) bool {
+	return false
+}
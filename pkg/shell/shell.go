@@ -0,0 +1,59 @@
+// Package shell runs commands the way kubernetes exec probes and handlers do.
+package shell
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Run runs the command with /bin/sh and returns its exit code. A non-zero exit code is returned along with an error
+// containing the output of the command. Once the context is done, the command is killed along with its descendants,
+// which would keep the output open otherwise, and the exit code is -1.
+func Run(ctx context.Context, command string) (int, error) {
+	out := new(bytes.Buffer)
+
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Env = os.Environ()
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = sysProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return -1, errors.Wrapf(err, "command %q failed to start", command)
+	}
+
+	waitCh := make(chan error, 1)
+	go func() {
+		waitCh <- cmd.Wait()
+	}()
+
+	var err error
+	select {
+	case err = <-waitCh:
+	case <-ctx.Done():
+		if err := killGroup(cmd.Process); err != nil {
+			log.Printf("failed to kill command %q: %s", command, err)
+		}
+		<-waitCh
+		return -1, errors.Wrapf(ctx.Err(), "command %q did not finish", command)
+	}
+
+	if err != nil {
+		code := -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		}
+		if output := strings.TrimSpace(out.String()); len(output) > 0 {
+			return code, errors.Wrapf(err, "command %q failed with output %q", command, output)
+		}
+		return code, errors.Wrapf(err, "command %q failed", command)
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package shell
+
+import (
+	"os"
+	"syscall"
+)
+
+// sysProcAttr starts the command in its own process group, so it can be killed along with its descendants.
+func sysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killGroup(proc *os.Process) error {
+	return syscall.Kill(-proc.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+// +build !windows
+
+package shell
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		timeout    time.Duration
+		wantCode   int
+		wantOutput string
+		wantErr    bool
+	}{
+		{name: "ok", command: "true", timeout: time.Second, wantCode: 0},
+		{name: "err_exit_code", command: "echo not ready; exit 3", timeout: time.Second, wantCode: 3, wantOutput: "not ready", wantErr: true},
+		// the forked sleep keeps the output open unless it is killed along with the shell
+		{name: "err_timeout_kills_descendants", command: "sleep 5; true", timeout: 100 * time.Millisecond, wantCode: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			start := time.Now()
+			code, err := Run(ctx, tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.wantOutput) {
+				t.Errorf("Run() error = %v, want it to contain %q", err, tt.wantOutput)
+			}
+			if code != tt.wantCode {
+				t.Errorf("Run() code = %d, want %d", code, tt.wantCode)
+			}
+			if elapsed := time.Since(start); elapsed > tt.timeout+time.Second {
+				t.Errorf("Run() returned after %s, timeout was %s", elapsed, tt.timeout)
+			}
+		})
+	}
+}
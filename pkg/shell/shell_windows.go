@@ -0,0 +1,15 @@
+package shell
+
+import (
+	"os"
+	"syscall"
+)
+
+func sysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// killGroup falls back to kill the command only, as there are no process groups on windows.
+func killGroup(proc *os.Process) error {
+	return proc.Kill()
+}
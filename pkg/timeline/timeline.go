@@ -0,0 +1,82 @@
+package timeline
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TimeFormat is the layout of times in reports, precise to the millisecond.
+const TimeFormat = "15:04:05.000"
+
+func New() *Timeline {
+	return &Timeline{
+		events: make([]Event, 0),
+	}
+}
+
+// Event is something that happened during a run, e.g. a line of output or a probe status change.
+type Event struct {
+	Time    time.Time
+	Source  string
+	Message string
+}
+
+// Timeline collects events from all parts of a run, so they can be correlated by time.
+type Timeline struct {
+	mu     sync.RWMutex
+	events []Event
+}
+
+func (tl *Timeline) Add(t time.Time, source, format string, args ...interface{}) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.events = append(tl.events, Event{Time: t, Source: source, Message: fmt.Sprintf(format, args...)})
+}
+
+// Merge adds the events of another timeline.
+func (tl *Timeline) Merge(other *Timeline) {
+	events := other.Events()
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.events = append(tl.events, events...)
+}
+
+// Events returns all events ordered by time. Events of the same time keep the order they were added in.
+func (tl *Timeline) Events() []Event {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	events := make([]Event, len(tl.events))
+	copy(events, tl.events)
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	return events
+}
+
+func (tl *Timeline) String() string {
+	events := tl.Events()
+
+	width := 0
+	for _, e := range events {
+		if len(e.Source) > width {
+			width = len(e.Source)
+		}
+	}
+
+	buf := bytes.NewBuffer([]byte(""))
+
+	for _, e := range events {
+		fmt.Fprintf(buf, "%s %-*s %s\n", e.Time.Format(TimeFormat), width, e.Source, e.Message)
+	}
+
+	return buf.String()
+}
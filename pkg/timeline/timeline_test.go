@@ -0,0 +1,43 @@
+package timeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeline_Events(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tl := New()
+	tl.Add(start.Add(2*time.Second), "stdout", "Closing http server")
+	tl.Add(start, "process", "status changed to %s", "running")
+	tl.Add(start.Add(time.Second), "signal", "sent SIGTERM")
+	tl.Add(start.Add(2*time.Second), "traffic", "connection refused")
+
+	want := []Event{
+		{Time: start, Source: "process", Message: "status changed to running"},
+		{Time: start.Add(time.Second), Source: "signal", Message: "sent SIGTERM"},
+		{Time: start.Add(2 * time.Second), Source: "stdout", Message: "Closing http server"},
+		{Time: start.Add(2 * time.Second), Source: "traffic", Message: "connection refused"},
+	}
+
+	if got := tl.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Events() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTimeline_String(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tl := New()
+	tl.Add(start, "stdout", "Listening on :8080")
+	tl.Add(start.Add(1500*time.Millisecond), "signal", "sent SIGTERM")
+
+	want := "12:00:00.000 stdout Listening on :8080\n" +
+		"12:00:01.500 signal sent SIGTERM\n"
+
+	if got := tl.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
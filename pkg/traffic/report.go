@@ -10,22 +10,40 @@ import (
 func NewSimulationReport() *SimulationReport {
 	return &SimulationReport{
 		httpCodes: make(httpCodesVec),
-		errors:    make([]error, 0),
+		errors:    make([]RequestError, 0),
 	}
 }
 
 type SimulationReport struct {
 	mu        sync.RWMutex
 	httpCodes httpCodesVec
-	errors    []error
+	errors    []RequestError
+	inFlight  int
+}
+
+// RequestError is a failed request of the simulation.
+type RequestError struct {
+	Time time.Time
+	Err  error
+}
+
+func (sr *SimulationReport) requestStarted() {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	sr.inFlight++
 }
 
 func (sr *SimulationReport) Record(statusCode int, elapsedTime time.Duration, err error) {
 	sr.mu.Lock()
 	defer sr.mu.Unlock()
 
+	if sr.inFlight > 0 {
+		sr.inFlight--
+	}
+
 	if err != nil {
-		sr.errors = append(sr.errors, err)
+		sr.errors = append(sr.errors, RequestError{Time: time.Now(), Err: err})
 	}
 
 	if statusCode > 0 {
@@ -33,6 +51,32 @@ func (sr *SimulationReport) Record(statusCode int, elapsedTime time.Duration, er
 	}
 }
 
+func (sr *SimulationReport) NumErrors() int {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	return len(sr.errors)
+}
+
+// InFlight returns the number of requests started but not finished yet.
+func (sr *SimulationReport) InFlight() int {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	return sr.inFlight
+}
+
+// Errors returns the failed requests in the order they were recorded.
+func (sr *SimulationReport) Errors() []RequestError {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+
+	errs := make([]RequestError, len(sr.errors))
+	copy(errs, sr.errors)
+
+	return errs
+}
+
 func (sr *SimulationReport) String() string {
 	sr.mu.RLock()
 	defer sr.mu.RUnlock()
@@ -46,13 +90,7 @@ func (sr *SimulationReport) String() string {
 
 	fmt.Fprint(buf, "\n")
 
-	fmt.Fprintf(buf, "num errors: %d", len(sr.errors))
-
-	if len(sr.errors) == 0 {
-		fmt.Fprint(buf, "GRACEFUL SHUTDOWN SUCCEED\n")
-	} else {
-		fmt.Fprintf(buf, "GRACEFUL SHUTDOWN FAILED WITH %d ERRORS!\n", len(sr.errors))
-	}
+	fmt.Fprintf(buf, "num errors: %d\n", len(sr.errors))
 
 	return buf.String()
 }
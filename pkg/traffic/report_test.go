@@ -0,0 +1,87 @@
+package traffic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestSimulationReport_InFlight(t *testing.T) {
+	sr := NewSimulationReport()
+
+	sr.requestStarted()
+	sr.requestStarted()
+	sr.requestStarted()
+	if got := sr.InFlight(); got != 3 {
+		t.Errorf("InFlight() = %d, want 3", got)
+	}
+
+	sr.Record(200, time.Millisecond, nil)
+	sr.Record(0, time.Millisecond, errors.New("connection refused"))
+	if got := sr.InFlight(); got != 1 {
+		t.Errorf("InFlight() = %d, want 1", got)
+	}
+
+	// a result without a started request must not turn the count negative
+	sr.Record(200, time.Millisecond, nil)
+	sr.Record(200, time.Millisecond, nil)
+	if got := sr.InFlight(); got != 0 {
+		t.Errorf("InFlight() = %d, want 0", got)
+	}
+	if got := sr.NumErrors(); got != 1 {
+		t.Errorf("NumErrors() = %d, want 1", got)
+	}
+}
+
+func Test_simulator_Simulate_inFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse url: %s", err)
+	}
+
+	s, err := NewSimulator(server.Client(), target, "GET", 2, 0)
+	if err != nil {
+		t.Fatalf("NewSimulator() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Simulate(ctx, wg)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Report().InFlight() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("InFlight() = %d, want 2 requests blocked by the server", s.Report().InFlight())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Simulate() did not return after cancel")
+	}
+
+	if got := s.Report().InFlight(); got != 0 {
+		t.Errorf("InFlight() = %d after the simulation, want 0", got)
+	}
+}
@@ -92,6 +92,7 @@ loop:
 		case <-ctx.Done():
 			break loop
 		default:
+			s.report.requestStarted()
 			statusCode, dur, err := s.performRequest()
 			s.report.Record(statusCode, dur, err)
 		}